@@ -0,0 +1,120 @@
+package bplustree
+
+import (
+	"math"
+)
+
+// Monoid folds values into an aggregate. Combine must be associative and
+// Identity must leave any value unchanged when combined with it on either
+// side. Values are combined in key order, so Combine need not commute.
+type Monoid struct {
+	Identity DataType
+	Combine  func(a, b DataType) DataType
+}
+
+// Sum, Min and Max aggregate the values of a key range into their sum,
+// their smallest and their greatest value. Min and Max of an empty range
+// are the greatest and the smallest DataType.
+var (
+	Sum = Monoid{0, func(a, b DataType) DataType { return a + b }}
+	Min = Monoid{math.MaxInt, func(a, b DataType) DataType { return min(a, b) }}
+	Max = Monoid{math.MinInt, func(a, b DataType) DataType { return max(a, b) }}
+)
+
+// WithAggregate caches in every node the aggregate of the values of its
+// subtree under m, so that Aggregate combines the cached partials of the
+// subtrees inside a range. Like the hashes of WithMerkle, the cached
+// aggregates of the nodes a change touches are dropped and recomputed from
+// their children on the next Aggregate.
+func WithAggregate(m Monoid) Option {
+	return func(tree *BPlusTree) {
+		tree.monoid = &m
+	}
+}
+
+// nodeAggregate returns the aggregate of the subtree rooted at n.
+func (tree *BPlusTree) nodeAggregate(n node) DataType {
+	bn := getNode(n)
+	if bn.aggregated {
+		return bn.agg
+	}
+	m := tree.monoid
+	a := m.Identity
+	if leaf, ok := n.(*bplusLeaf); ok {
+		for i := 0; i < leaf.entries; i++ {
+			a = m.Combine(a, leaf.kvs[i].value)
+		}
+	} else {
+		nl := n.(*bplusNonLeaf)
+		for i := 0; i < nl.children; i++ {
+			a = m.Combine(a, tree.nodeAggregate(nl.subPtr[i]))
+		}
+	}
+	bn.agg, bn.aggregated = a, true
+	return a
+}
+
+// Aggregate returns the aggregate of the values whose keys lie between lo
+// and hi inclusive, the monoid's identity for an empty range. Subtrees lying
+// inside the range contribute their cached aggregate, so only the nodes on
+// the paths to lo and hi are read. The cached aggregates count expired
+// pairs, so those are removed first by ExpireBefore; while a transaction
+// holds expiry back, the live pairs of the range are folded one by one
+// instead. The tree must be created WithAggregate.
+func (tree *BPlusTree) Aggregate(lo, hi KeyType) DataType {
+	assert(tree.monoid != nil)
+	m := tree.monoid
+	a := m.Identity
+	if tree.hasExpired() {
+		if tree.txn == nil {
+			tree.ExpireBefore(tree.now())
+		} else {
+			for leaf, i := tree.seekFirst(lo); leaf != nil && leaf.kvs[i].key <= hi; leaf, i = tree.nextPos(leaf, i) {
+				if !tree.expired(leaf.kvs[i].key) {
+					a = m.Combine(a, leaf.kvs[i].value)
+				}
+			}
+			return a
+		}
+	}
+	if tree.root == nil || lo > hi {
+		return a
+	}
+	b := keyBounds{lo: lo, hasLo: true, hi: hi + 1, hasHi: true}
+	if hi == math.MaxInt {
+		b.hasHi = false
+	}
+	var walk func(nd node, nb keyBounds)
+	walk = func(nd node, nb keyBounds) {
+		if leaf, ok := nd.(*bplusLeaf); ok {
+			for i := 0; i < leaf.entries; i++ {
+				if b.contains(leaf.kvs[i].key) {
+					a = m.Combine(a, leaf.kvs[i].value)
+				}
+			}
+			return
+		}
+		nl := nd.(*bplusNonLeaf)
+		for i := 0; i < nl.children; i++ {
+			cb := nb.child(nl, i)
+			if tree.multi && cb.hasHi {
+				/* keys equal to the separator may sit on its left */
+				if cb.hi == math.MaxInt {
+					cb.hasHi = false
+				} else {
+					cb.hi++
+				}
+			}
+			if b.disjoint(cb) {
+				continue
+			}
+			if b.covers(cb) {
+				a = m.Combine(a, tree.nodeAggregate(nl.subPtr[i]))
+				continue
+			}
+			walk(nl.subPtr[i], cb)
+		}
+	}
+	walk(tree.root, keyBounds{})
+	return a
+}
@@ -0,0 +1,50 @@
+package bplustree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, multi := range []bool{false, true} {
+		for _, m := range []struct {
+			name   string
+			monoid Monoid
+		}{{"Sum", Sum}, {"Min", Min}, {"Max", Max}} {
+			opts := []Option{WithAggregate(m.monoid)}
+			if multi {
+				opts = append(opts, Multi())
+			}
+			tree := New(3, 3, opts...)
+			for i := 0; i < 5000; i++ {
+				key := r.Intn(300)
+				switch r.Intn(4) {
+				case 0:
+					tree.Delete(key)
+				case 1:
+					tree.Update(key, r.Intn(1000)-500)
+				default:
+					tree.Insert(key, r.Intn(1000)-500)
+				}
+				if i%23 != 0 {
+					continue
+				}
+				lo := r.Intn(320) - 10
+				hi := lo + r.Intn(150)
+				want := m.monoid.Identity
+				for it := tree.First(); it.Valid(); it.Next() {
+					if it.Key() >= lo && it.Key() <= hi {
+						want = m.monoid.Combine(want, it.Value())
+					}
+				}
+				if got := tree.Aggregate(lo, hi); got != want {
+					t.Fatalf("%s multi=%v step %d: Aggregate(%d, %d) = %d, want %d", m.name, multi, i, lo, hi, got, want)
+				}
+				if err := tree.Verify(); err != nil {
+					t.Fatalf("%s multi=%v step %d: %v", m.name, multi, i, err)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package bplustree
+
+import (
+	"cmp"
+	"slices"
+)
+
+// batchOrder returns the positions of keys in ascending key order, equal
+// keys keeping their batch order. It is nil when keys are already sorted.
+func batchOrder(keys []KeyType) []int {
+	if slices.IsSorted(keys) {
+		return nil
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(keys[a], keys[b])
+	})
+	return order
+}
+
+// batchEach calls fn with the batch positions in ascending key order.
+func batchEach(keys []KeyType, fn func(pos int)) {
+	order := batchOrder(keys)
+	for i := range keys {
+		if order != nil {
+			fn(order[i])
+		} else {
+			fn(i)
+		}
+	}
+}
+
+// InsertMany inserts keys[i] with values[i] for every i and returns the
+// result Insert would give for each pair, in batch order. The batch is
+// sorted if needed and the tree walked once: the pairs falling in the same
+// leaf are added to it as a run, past its capacity if need be, and the leaf
+// is split once at the end of the run into as many leaves as the pairs
+// need. Equal keys in the batch are applied in batch order.
+func (tree *BPlusTree) InsertMany(keys []KeyType, values []DataType) []int {
+	assert(len(keys) == len(values))
+	ret := make([]int, len(keys))
+	c := leafCursor{tree: tree}
+	var run *bplusLeaf
+	batchEach(keys, func(pos int) {
+		key, data := keys[pos], values[pos]
+		if tree.expired(key) {
+			/* the removal may rebalance the leaf of the run */
+			if run != nil {
+				tree.leafSplitRun(run)
+				run = nil
+			}
+			tree.expire(key)
+		}
+		leaf := c.seek(key)
+		if run != nil && (leaf != run || run.entries == MaxEntries) {
+			/* the run has ended, a split bumps the version and the key descends again */
+			tree.leafSplitRun(run)
+			run = nil
+			leaf = c.seek(key)
+		}
+		if leaf == nil {
+			ret[pos] = tree.insert(key, data)
+			return
+		}
+		if leaf.entries == MaxEntries {
+			/* no room to run past a full leaf of the largest size */
+			ret[pos] = tree.leafInsert(leaf, key, data)
+			return
+		}
+		insert, ok := leaf.keySearch(key)
+		if ok {
+			if !tree.multi {
+				ret[pos] = -1
+				return
+			}
+			/* append after the equal keys */
+			insert++
+		}
+		tree.inserted(leaf, key, data)
+		leaf.simpleInsert(key, data, insert)
+		run = leaf
+	})
+	if run != nil {
+		tree.leafSplitRun(run)
+	}
+	tree.evict()
+	return ret
+}
+
+// leafSplitRun splits a leaf that InsertMany has filled past its capacity
+// into as few leaves as hold its pairs, each taking an even share.
+func (tree *BPlusTree) leafSplitRun(leaf *bplusLeaf) {
+	n := leaf.entries
+	if n <= tree.entries {
+		tree.touch(leaf)
+		return
+	}
+	tree.version++
+	pieces := (n + tree.entries - 1) / tree.entries
+	siblings := make([]*bplusLeaf, pieces-1)
+	last := leaf
+	for p := range siblings {
+		sibling := leafNew()
+		sibling.entries = copy(sibling.kvs[:], leaf.kvs[(p+1)*n/pieces:(p+2)*n/pieces])
+		last.listAdd(sibling, last.next)
+		siblings[p], last = sibling, sibling
+	}
+	leaf.entries = n / pieces
+	/* the pairs moved out count again once their leaf hangs in the tree */
+	resize(leaf, leaf.entries-n)
+
+	left := leaf
+	for _, right := range siblings {
+		resize(left, right.entries)
+		tree.counters.leafSplits++
+		if tree.observer != nil {
+			tree.observer.OnLeafSplit(SplitEvent{Left: nodeRange(left), Right: nodeRange(right), SplitKey: right.kvs[0].key})
+		}
+		/* build new parent */
+		tree.parentNodeBuild(left, right, right.kvs[0].key, 0)
+		left = right
+	}
+	tree.touch(leaf)
+	for _, sibling := range siblings {
+		tree.touch(sibling)
+	}
+}
+
+// DeleteMany removes every key of the batch and returns the result Delete
+// would give for each key, in batch order. Like InsertMany it walks the
+// tree once, removing the keys falling in the same leaf as a run, below
+// its minimum fill if need be, and rebalances the leaf with a sibling once
+// at the end of the run.
+func (tree *BPlusTree) DeleteMany(keys []KeyType) []int {
+	ret := make([]int, len(keys))
+	c := leafCursor{tree: tree, lower: tree.multi}
+	var run *bplusLeaf
+	/* a borrow or merge bumps the version and the next key descends again */
+	endRun := func() {
+		if run != nil {
+			tree.leafRebalanceRun(run)
+			run = nil
+		}
+	}
+	batchEach(keys, func(pos int) {
+		key := keys[pos]
+		leaf := c.seek(key)
+		if run != nil && leaf != run {
+			endRun()
+			leaf = c.seek(key)
+		}
+		if leaf == nil {
+			ret[pos] = -1
+			return
+		}
+		var remove int
+		var found bool
+		if tree.multi {
+			remove = leaf.keyLowerSearch(key)
+			if remove == leaf.entries {
+				/* the first equal key lies in a following leaf */
+				endRun()
+				ret[pos] = tree.Delete(key)
+				return
+			}
+			found = leaf.kvs[remove].key == key
+		} else {
+			remove, found = leaf.keySearch(key)
+		}
+		if !found {
+			ret[pos] = -1
+			return
+		}
+		tree.removed(leaf, remove)
+		leaf.simpleRemove(remove)
+		run = leaf
+	})
+	endRun()
+	return ret
+}
+
+// leafRebalanceRun restores the minimum fill of a leaf that DeleteMany has
+// removed pairs from, dropping the root leaf once it is empty.
+func (tree *BPlusTree) leafRebalanceRun(leaf *bplusLeaf) {
+	if leaf.parent == nil {
+		if leaf.entries == 0 {
+			/* delete the only last node */
+			tree.root = nil
+			tree.version++
+			leaf.delete()
+		} else {
+			tree.touch(leaf)
+		}
+		return
+	}
+	if leaf.entries < (tree.entries+1)/2 {
+		tree.version++
+	}
+	tree.rebalanceLeaf(leaf)
+}
+
+// SearchMany looks up every key of the batch and returns the values and
+// whether each key was found, in batch order, with the same expiry and
+// use tracking as Search. The tree is walked once, reusing the current leaf
+// while the following keys fall in its range.
+func (tree *BPlusTree) SearchMany(keys []KeyType) ([]DataType, []bool) {
+	values := make([]DataType, len(keys))
+	found := make([]bool, len(keys))
+	c := leafCursor{tree: tree, lower: tree.multi}
+	batchEach(keys, func(pos int) {
+		key := keys[pos]
+		if tree.expire(key) {
+			return
+		}
+		leaf := c.seek(key)
+		if leaf == nil {
+			return
+		}
+		if tree.multi {
+			i := leaf.keyLowerSearch(key)
+			if i == leaf.entries {
+				/* the first equal key lies in a following leaf */
+				values[pos], found[pos] = tree.Search(key)
+				return
+			}
+			if leaf.kvs[i].key == key {
+				values[pos], found[pos] = leaf.kvs[i].value, true
+				tree.used(key)
+			}
+			return
+		}
+		if i, ok := leaf.keySearch(key); ok {
+			values[pos], found[pos] = leaf.kvs[i].value, true
+			tree.used(key)
+		}
+	})
+	return values, found
+}
@@ -0,0 +1,139 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBatch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, cfg := range []struct {
+		order, entries int
+		multi          bool
+	}{{3, 3, false}, {4, 5, false}, {MaxOrder, MaxEntries, false}, {3, 3, true}, {5, 4, true}} {
+		var opts []Option
+		if cfg.multi {
+			opts = append(opts, Multi())
+		}
+		batch, single := New(cfg.order, cfg.entries, opts...), New(cfg.order, cfg.entries, opts...)
+		for round := 0; round < 200; round++ {
+			keys := make([]KeyType, r.Intn(60))
+			values := make([]DataType, len(keys))
+			for i := range keys {
+				keys[i], values[i] = r.Intn(300), round*100+i
+			}
+			if r.Intn(2) == 0 {
+				slices.Sort(keys)
+			}
+
+			var got, want []int
+			switch r.Intn(3) {
+			case 0:
+				got = batch.InsertMany(keys, values)
+				for _, pos := range stableOrder(keys) {
+					want = append(want, single.Insert(keys[pos], values[pos]))
+				}
+				want = unorder(keys, want)
+			case 1:
+				got = batch.DeleteMany(keys)
+				for _, pos := range stableOrder(keys) {
+					want = append(want, single.Delete(keys[pos]))
+				}
+				want = unorder(keys, want)
+			default:
+				vals, found := batch.SearchMany(keys)
+				for i, key := range keys {
+					v, ok := single.Search(key)
+					if ok != found[i] || v != vals[i] {
+						t.Fatalf("%+v: SearchMany(%d) = %d, %v, want %d, %v", cfg, key, vals[i], found[i], v, ok)
+					}
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Fatalf("%+v round %d: results %v, want %v", cfg, round, got, want)
+			}
+			if err := batch.Verify(); err != nil {
+				t.Fatalf("%+v round %d: %v", cfg, round, err)
+			}
+			var a, b []KeyType
+			for k, v := range batch.Ascend(0, 1000) {
+				a = append(a, k, v)
+			}
+			for k, v := range single.Ascend(0, 1000) {
+				b = append(b, k, v)
+			}
+			if !slices.Equal(a, b) {
+				t.Fatalf("%+v round %d: contents differ\n%v\n%v", cfg, round, a, b)
+			}
+		}
+	}
+}
+
+func stableOrder(keys []KeyType) []int {
+	if order := batchOrder(keys); order != nil {
+		return order
+	}
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+/** map results listed in key order back to batch order */
+func unorder(keys []KeyType, sorted []int) []int {
+	ret := make([]int, len(keys))
+	for i, pos := range stableOrder(keys) {
+		ret[pos] = sorted[i]
+	}
+	return ret
+}
+
+func TestBatchRuns(t *testing.T) {
+	batch, single := New(4, 8), New(4, 8)
+	keys := make([]KeyType, 1000)
+	for i := range keys {
+		keys[i] = i
+		single.Insert(i, i)
+	}
+	batch.InsertMany(keys, keys)
+	/* a run splits its leaf once, into full leaves */
+	if st := batch.Stats(); st.LeafNodes != 125 || st.LeafSplits != 124 {
+		t.Fatalf("%d leaves after %d splits", st.LeafNodes, st.LeafSplits)
+	}
+	batch.DeleteMany(keys[8:992])
+	for _, k := range keys[8:992] {
+		single.Delete(k)
+	}
+	bs, ss := batch.Stats(), single.Stats()
+	if n := bs.LeafMerges + bs.LeafBorrows; n > 123 || n >= ss.LeafMerges+ss.LeafBorrows {
+		t.Fatalf("%d merges and borrows, %d one key at a time", n, ss.LeafMerges+ss.LeafBorrows)
+	}
+	if err := batch.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchManyExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tree := New(3, 3, WithClock(func() time.Time { return now }), WithLimit(4, 0, EvictLRU))
+	for k := 0; k < 4; k++ {
+		tree.InsertWithTTL(k, k, time.Duration(k+1)*time.Minute)
+	}
+	now = now.Add(90 * time.Second)
+	if _, found := tree.SearchMany([]KeyType{0, 1}); found[0] || !found[1] || tree.Len() != 3 {
+		t.Fatalf("expired key 0 found %v, %d keys left", found, tree.Len())
+	}
+	/* 1 was used last, 2 is the least recently used now */
+	tree.Insert(4, 4)
+	tree.Insert(5, 5)
+	if _, ok := tree.Search(2); ok {
+		t.Fatal("2 not evicted")
+	}
+	if _, ok := tree.Search(1); !ok {
+		t.Fatal("1 evicted after SearchMany used it")
+	}
+}
@@ -1,7 +1,7 @@
 package bplustree
 
 import (
-	"fmt"
+	"time"
 )
 
 const (
@@ -23,10 +23,20 @@ type bplusNode struct {
 	typ          nodeType      // leaf or nonLeaf
 	parentKeyIdx int           // index of parent node
 	parent       *bplusNonLeaf // pointer to parent node
+	hash         uint64        // sum of the entry hashes of the subtree
+	hashed       bool          // hash is up to date
+	agg          DataType      // aggregate of the values of the subtree
+	aggregated   bool          // agg is up to date
 }
 
 type node interface{}
 
+/** drop the cached hash and aggregate of the node */
+func (bn *bplusNode) stale() {
+	bn.hashed = false
+	bn.aggregated = false
+}
+
 func getNode(n node) *bplusNode {
 	if v, ok := n.(*bplusLeaf); ok {
 		return &v.bplusNode
@@ -44,6 +54,31 @@ type bplusNonLeaf struct {
 	key [MaxOrder - 1]KeyType
 	/** pointers to child node */
 	subPtr [MaxOrder]node
+	/** number of key-value pairs in the subtree */
+	size int
+}
+
+/** number of key-value pairs in the subtree rooted at n */
+func subtreeSize(n node) int {
+	if leaf, ok := n.(*bplusLeaf); ok {
+		return leaf.entries
+	}
+	return n.(*bplusNonLeaf).size
+}
+
+/** add d to the subtree sizes of the ancestors of n */
+func resize(n node, d int) {
+	for p := getNode(n).parent; p != nil; p = p.parent {
+		p.size += d
+	}
+}
+
+/** recompute the subtree size of the node from its children */
+func (nl *bplusNonLeaf) recount() {
+	nl.size = 0
+	for i := 0; i < nl.children; i++ {
+		nl.size += subtreeSize(nl.subPtr[i])
+	}
 }
 
 func (nl *bplusNonLeaf) keySearch(target KeyType) (int, bool) {
@@ -63,6 +98,19 @@ func (nl *bplusNonLeaf) keySearch(target KeyType) (int, bool) {
 	return i, false
 }
 
+func (nl *bplusNonLeaf) keyLowerSearch(target KeyType) int {
+	i, j := 0, nl.children-1
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if nl.key[h] < target {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
 func (nl *bplusNonLeaf) listAdd(link *bplusNonLeaf, next *bplusNonLeaf) {
 	link.next = next
 	link.prev = nl
@@ -262,22 +310,21 @@ func (nl *bplusNonLeaf) splitRight1(right *bplusNonLeaf, lCh, rCh node, key KeyT
 	var order = nl.children
 	/* split as right sibling */
 	nl.listAdd(right, nl.next)
-	/* split key is key[split - 1] */
-	splitKey := nl.key[split-1]
-	/* left node's children always be [split] */
-	nl.children = split
-	/* right node's first sub-node */
-	right.key[0] = key
-	right.subPtr[0] = lCh
+	/* insertion point is split point, the new key moves up */
+	splitKey := key
+	/* left node's children always be [split + 1], the last one is lCh */
+	nl.subPtr[split] = lCh
 	lbn := getNode(lCh)
-	lbn.parent = right
-	lbn.parentKeyIdx = -1
-	right.subPtr[1] = rCh
+	lbn.parent = nl
+	lbn.parentKeyIdx = split - 1
+	nl.children = split + 1
+	/* right node's first sub-node */
+	right.subPtr[0] = rCh
 	rbn := getNode(rCh)
 	rbn.parent = right
-	rbn.parentKeyIdx = 0
-	/* insertion point is split point, replicate from key[split] */
-	for i, j = split, 1; i < order-1; {
+	rbn.parentKeyIdx = -1
+	/* replicate from key[split] */
+	for i, j = split, 0; i < order-1; {
 		right.key[j] = nl.key[i]
 		right.subPtr[j+1] = nl.subPtr[i+1]
 		rcbn := getNode(right.subPtr[j+1])
@@ -369,6 +416,19 @@ func (leaf *bplusLeaf) keySearch(target KeyType) (int, bool) {
 	return i, false
 }
 
+func (leaf *bplusLeaf) keyLowerSearch(target KeyType) int {
+	i, j := 0, leaf.entries
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if leaf.kvs[h].key < target {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
 func (leaf *bplusLeaf) listAdd(link *bplusLeaf, next *bplusLeaf) {
 	link.next = next
 	link.prev = leaf
@@ -490,17 +550,54 @@ type BPlusTree struct {
 	/** height of the tree */
 	level int
 	root  node
+	/** number of key-value pairs stored in the tree */
+	count int
+	/** equal keys are allowed and kept in insertion order */
+	multi bool
+	/** cumulative structural changes since creation */
+	counters treeCounters
+	/** receives structural changes, may be nil */
+	observer Observer
+	/** bumped whenever nodes are split, merged, rebalanced or dropped */
+	version uint64
+	/** bumped by every insert and removal, for iterators */
+	mods uint64
+	/** the open transaction, if any */
+	txn *Txn
+	/** retained change records, nil unless enabled */
+	changes *changeLog
+	/** node hashes are cached and kept up to date */
+	merkle bool
+	/** combines values into the cached node aggregates, nil unless enabled */
+	monoid *Monoid
+	/** deadlines of the keys inserted with a TTL, nil until the first */
+	ttl *ttlIndex
+	/** current time for expiry, time.Now if nil */
+	clock func() time.Time
+	/** size cap and eviction state, nil if unbounded */
+	limit *evictLimit
 
 	firstLeaf *bplusLeaf
 }
 
+// Option configures optional behaviour of a BPlusTree.
+type Option func(tree *BPlusTree)
+
+// Multi makes the tree a multimap: Insert accepts keys that are already
+// present and stores equal keys side by side in insertion order.
+func Multi() Option {
+	return func(tree *BPlusTree) {
+		tree.multi = true
+	}
+}
+
 func assert(ok bool) {
 	if !ok {
 		panic("ok")
 	}
 }
 
-func New(order int, entries int) *BPlusTree {
+func New(order int, entries int, opts ...Option) *BPlusTree {
 	/* The max order of non leaf nodes must be more than two */
 	assert(order <= MaxOrder && entries <= MaxEntries)
 
@@ -508,6 +605,12 @@ func New(order int, entries int) *BPlusTree {
 	tree.root = nil
 	tree.order = order
 	tree.entries = entries
+	for _, opt := range opts {
+		opt(tree)
+	}
+	if tree.multi && tree.limit != nil && tree.limit.policy == EvictLRU {
+		panic("bplustree: EvictLRU cannot be combined with Multi")
+	}
 	return tree
 }
 
@@ -543,25 +646,27 @@ func (tree *BPlusTree) parentNodeBuild(left node, right node, key KeyType, level
 		rn.parent = parent
 		rn.parentKeyIdx = 0
 		parent.children = 2
+		parent.recount()
 		/* update root */
 		tree.root = parent
 		tree.level++
+		if tree.observer != nil {
+			tree.observer.OnRootGrow(tree.level)
+		}
 		return 0
 	} else if rn.parent == nil {
 		/* trace upwards */
 		rn.parent = ln.parent
-		return tree.nonLeafInsert(ln.parent, left, right, key, level+1)
+		return tree.nonLeafInsert(ln.parent, left, right, key, ln.parentKeyIdx+1, level+1)
 	} else {
 		/* trace upwards */
 		ln.parent = rn.parent
-		return tree.nonLeafInsert(rn.parent, left, right, key, level+1)
+		return tree.nonLeafInsert(rn.parent, left, right, key, rn.parentKeyIdx+1, level+1)
 	}
 }
 
-func (tree *BPlusTree) nonLeafInsert(node *bplusNonLeaf, lCh node, rCh node, key KeyType, level int) int {
-	/* search key location */
-	insert, ok := node.keySearch(key)
-	assert(!ok)
+func (tree *BPlusTree) nonLeafInsert(node *bplusNonLeaf, lCh node, rCh node, key KeyType, insert int, level int) int {
+	/* insert is the slot of the split child, equal keys may repeat in multi mode */
 
 	/* node is full */
 	if node.children == tree.order {
@@ -569,6 +674,7 @@ func (tree *BPlusTree) nonLeafInsert(node *bplusNonLeaf, lCh node, rCh node, key
 		var splitKey KeyType
 		split := node.children / 2
 		sibling := nonLeafNew()
+		tree.counters.innerSplits++
 		if insert < split {
 			splitKey = node.splitLeft(sibling, lCh, rCh, key, insert, split)
 		} else if insert == split {
@@ -576,12 +682,20 @@ func (tree *BPlusTree) nonLeafInsert(node *bplusNonLeaf, lCh node, rCh node, key
 		} else {
 			splitKey = node.splitRight2(sibling, lCh, rCh, key, insert, split)
 		}
-		/* build new parent */
+		left, right := node, sibling
 		if insert < split {
-			return tree.parentNodeBuild(sibling, node, splitKey, level)
-		} else {
-			return tree.parentNodeBuild(node, sibling, splitKey, level)
+			left, right = sibling, node
+		}
+		/* either half may hold neither split child, the walk from the leaf misses it */
+		left.stale()
+		right.stale()
+		left.recount()
+		right.recount()
+		if tree.observer != nil {
+			tree.observer.OnInnerSplit(SplitEvent{Level: level, Left: nodeRange(left), Right: nodeRange(right), SplitKey: splitKey})
 		}
+		/* build new parent */
+		return tree.parentNodeBuild(left, right, splitKey, level)
 	} else {
 		node.simpleInsert(lCh, rCh, key, insert)
 	}
@@ -592,9 +706,14 @@ func (tree *BPlusTree) leafInsert(leaf *bplusLeaf, key KeyType, data DataType) i
 	/* search key location */
 	insert, ok := leaf.keySearch(key)
 	if ok {
-		/* Already exists */
-		return -1
+		if !tree.multi {
+			/* Already exists */
+			return -1
+		}
+		/* append after the equal keys */
+		insert++
 	}
+	tree.inserted(leaf, key, data)
 
 	/* node full */
 	if leaf.entries == tree.entries {
@@ -602,44 +721,89 @@ func (tree *BPlusTree) leafInsert(leaf *bplusLeaf, key KeyType, data DataType) i
 		split := (tree.entries + 1) / 2
 		/* split sibling node */
 		sibling := leafNew()
+		tree.counters.leafSplits++
+		tree.version++
 		/* sibling leaf replication due to location of insertion */
 		if insert < split {
 			leaf.splitLeft(sibling, key, data, insert)
+			if leaf == tree.firstLeaf {
+				tree.firstLeaf = sibling
+			}
 		} else {
 			leaf.splitRight(sibling, key, data, insert)
 		}
-		/* build new parent */
+		left, right := leaf, sibling
 		if insert < split {
-			return tree.parentNodeBuild(sibling, leaf, leaf.kvs[0].key, 0)
-		} else {
-			return tree.parentNodeBuild(leaf, sibling, sibling.kvs[0].key, 0)
+			left, right = sibling, leaf
+		}
+		if tree.observer != nil {
+			tree.observer.OnLeafSplit(SplitEvent{Left: nodeRange(left), Right: nodeRange(right), SplitKey: right.kvs[0].key})
 		}
+		/* build new parent */
+		ret := tree.parentNodeBuild(left, right, right.kvs[0].key, 0)
+		tree.touch(left)
+		tree.touch(right)
+		return ret
 	} else {
 		leaf.simpleInsert(key, data, insert)
+		tree.touch(leaf)
 	}
 	return 0
 }
 
-func (tree *BPlusTree) leafRemove(leaf *bplusLeaf, key KeyType) int {
-	remove, ok := leaf.keySearch(key)
-	if !ok {
-		/* Not exist */
-		return -1
+// inserted accounts for a pair about to be added to leaf.
+func (tree *BPlusTree) inserted(leaf *bplusLeaf, key KeyType, data DataType) {
+	resize(leaf, 1)
+	tree.count++
+	tree.mods++
+	if tree.changes != nil {
+		tree.changes.add(ChangeInsert, key, 0, data)
+	}
+	tree.used(key)
+}
+
+// removed accounts for the pair at i about to be removed from leaf.
+func (tree *BPlusTree) removed(leaf *bplusLeaf, i int) {
+	key, value := leaf.kvs[i].key, leaf.kvs[i].value
+	resize(leaf, -1)
+	tree.count--
+	tree.mods++
+	if tree.ttl != nil {
+		tree.forget(key)
+	}
+	tree.unused(key)
+	if tree.changes != nil {
+		tree.changes.add(ChangeDelete, key, value, 0)
 	}
+}
 
+func (tree *BPlusTree) leafRemove(leaf *bplusLeaf, remove int) {
+	tree.removed(leaf, remove)
 	if leaf.entries <= (tree.entries+1)/2 {
 		parent := leaf.parent
 		if parent != nil {
+			tree.version++
 			/* decide which sibling to be borrowed from */
 			i := leaf.parentKeyIdx
 			if leaf.siblingSelect(parent, i) {
 				lSib := leaf.prev
 				if lSib.entries > (tree.entries+1)/2 {
 					leaf.shiftFromLeft(lSib, i, remove)
+					tree.touch(leaf)
+					tree.touch(lSib)
+					tree.counters.leafBorrows++
+					if tree.observer != nil {
+						tree.observer.OnBorrow(BorrowEvent{FromLeft: true, Node: nodeRange(leaf), Sibling: nodeRange(lSib)})
+					}
 				} else {
 					leaf.mergeIntoLeft(lSib, remove)
+					tree.counters.leafMerges++
+					if tree.observer != nil {
+						tree.observer.OnMerge(MergeEvent{Merged: nodeRange(lSib)})
+					}
 					/* trace upwards */
-					tree.nonLeafRemove(parent, i)
+					tree.nonLeafRemove(parent, i, 1)
+					tree.touch(lSib)
 				}
 			} else {
 				rSib := leaf.next
@@ -647,31 +811,52 @@ func (tree *BPlusTree) leafRemove(leaf *bplusLeaf, key KeyType) int {
 				leaf.simpleRemove(remove)
 				if rSib.entries > (tree.entries+1)/2 {
 					leaf.shiftFromRight(rSib, i+1)
+					tree.touch(leaf)
+					tree.touch(rSib)
+					tree.counters.leafBorrows++
+					if tree.observer != nil {
+						tree.observer.OnBorrow(BorrowEvent{Node: nodeRange(leaf), Sibling: nodeRange(rSib)})
+					}
 				} else {
 					leaf.mergeFromRight(rSib)
+					tree.counters.leafMerges++
+					if tree.observer != nil {
+						tree.observer.OnMerge(MergeEvent{Merged: nodeRange(leaf)})
+					}
 					/* trace upwards */
-					tree.nonLeafRemove(parent, i+1)
+					tree.nonLeafRemove(parent, i+1, 1)
+					tree.touch(leaf)
 				}
 			}
 		} else {
 			if leaf.entries == 1 {
 				/* delete the only last node */
-				assert(key == leaf.kvs[0].key)
+				assert(remove == 0)
 				tree.root = nil
+				tree.version++
 				leaf.delete()
-				return 0
+				return
 			} else {
 				leaf.simpleRemove(remove)
+				tree.touch(leaf)
 			}
 		}
 	} else {
 		leaf.simpleRemove(remove)
+		tree.touch(leaf)
 	}
-
-	return 0
 }
 
 func (tree *BPlusTree) Insert(key KeyType, data DataType) int {
+	tree.expire(key)
+	ret := tree.insert(key, data)
+	if ret == 0 {
+		tree.evict()
+	}
+	return ret
+}
+
+func (tree *BPlusTree) insert(key KeyType, data DataType) int {
 	node := tree.root
 	for node != nil {
 		if ln, ok := node.(*bplusLeaf); ok {
@@ -692,12 +877,45 @@ func (tree *BPlusTree) Insert(key KeyType, data DataType) int {
 	root.kvs[0].value = data
 	root.entries = 1
 	tree.root = root
+	tree.count = 1
+	tree.version++
+	tree.mods++
+	if tree.changes != nil {
+		tree.changes.add(ChangeInsert, key, 0, data)
+	}
+	tree.used(key)
 
 	tree.firstLeaf = root
 	return 0
 }
 
+// Len returns the number of key-value pairs stored in the tree.
+func (tree *BPlusTree) Len() int {
+	return tree.count
+}
+
+// Height returns the number of node levels, 0 for an empty tree. It is
+// the number of nodes visited by a descent from the root to a leaf.
+func (tree *BPlusTree) Height() int {
+	if tree.root == nil {
+		return 0
+	}
+	return tree.level + 1
+}
+
 func (tree *BPlusTree) Search(key KeyType) (ret DataType, ok bool) {
+	if tree.expire(key) {
+		return
+	}
+	if tree.multi {
+		/* the first of the equal keys */
+		if leaf, i := tree.seekFirst(key); leaf != nil && leaf.kvs[i].key == key {
+			tree.used(key)
+			return leaf.kvs[i].value, true
+		}
+		return
+	}
+
 	node := tree.root
 	for node != nil {
 		if ln, success := node.(*bplusLeaf); success {
@@ -705,6 +923,7 @@ func (tree *BPlusTree) Search(key KeyType) (ret DataType, ok bool) {
 			if found {
 				ok = true
 				ret = ln.kvs[i].value
+				tree.used(key)
 			}
 			break
 		} else {
@@ -720,7 +939,50 @@ func (tree *BPlusTree) Search(key KeyType) (ret DataType, ok bool) {
 	return
 }
 
-func (tree *BPlusTree) nonLeafRemove(node *bplusNonLeaf, remove int) {
+// Update replaces the value stored under key, the first of the equal keys
+// in multi mode. It returns -1 if key does not exist or has expired.
+func (tree *BPlusTree) Update(key KeyType, data DataType) int {
+	if tree.expire(key) {
+		return -1
+	}
+	leaf, i := tree.seekFirst(key)
+	if leaf == nil || leaf.kvs[i].key != key {
+		return -1
+	}
+	if tree.changes != nil {
+		tree.changes.add(ChangeUpdate, key, leaf.kvs[i].value, data)
+	}
+	leaf.kvs[i].value = data
+	tree.touch(leaf)
+	tree.used(key)
+	return 0
+}
+
+// seekFirst returns the position of the first key not less than key, or a
+// nil leaf when every key is less.
+func (tree *BPlusTree) seekFirst(key KeyType) (*bplusLeaf, int) {
+	node := tree.root
+	for node != nil {
+		if ln, ok := node.(*bplusLeaf); ok {
+			i := ln.keyLowerSearch(key)
+			/* equal keys may start in the next leaf */
+			for i >= ln.entries {
+				if tree.listIsLastLeaf(ln) {
+					return nil, 0
+				}
+				ln = ln.next
+				i = 0
+			}
+			return ln, i
+		} else {
+			nln := node.(*bplusNonLeaf)
+			node = nln.subPtr[nln.keyLowerSearch(key)]
+		}
+	}
+	return nil, 0
+}
+
+func (tree *BPlusTree) nonLeafRemove(node *bplusNonLeaf, remove int, level int) {
 	if node.children <= (tree.order+1)/2 {
 		parent := node.parent
 		if parent != nil {
@@ -730,10 +992,23 @@ func (tree *BPlusTree) nonLeafRemove(node *bplusNonLeaf, remove int) {
 				sib := node.prev
 				if sib.children > (tree.order+1)/2 {
 					node.shiftFromLeft(sib, i, remove)
+					node.recount()
+					sib.recount()
+					sib.stale()
+					tree.counters.innerBorrows++
+					if tree.observer != nil {
+						tree.observer.OnBorrow(BorrowEvent{Level: level, FromLeft: true, Node: nodeRange(node), Sibling: nodeRange(sib)})
+					}
 				} else {
 					node.mergeIntoLeft(sib, i, remove)
+					sib.recount()
+					sib.stale()
+					tree.counters.innerMerges++
+					if tree.observer != nil {
+						tree.observer.OnMerge(MergeEvent{Level: level, Merged: nodeRange(sib)})
+					}
 					/* trace upwards */
-					tree.nonLeafRemove(parent, i)
+					tree.nonLeafRemove(parent, i, level+1)
 				}
 			} else { // right
 				sib := node.next
@@ -741,10 +1016,22 @@ func (tree *BPlusTree) nonLeafRemove(node *bplusNonLeaf, remove int) {
 				node.simpleRemove(remove)
 				if sib.children > (tree.order+1)/2 {
 					node.shiftFromRight(sib, i+1)
+					node.recount()
+					sib.recount()
+					sib.stale()
+					tree.counters.innerBorrows++
+					if tree.observer != nil {
+						tree.observer.OnBorrow(BorrowEvent{Level: level, Node: nodeRange(node), Sibling: nodeRange(sib)})
+					}
 				} else {
 					node.mergeFromRight(sib, i+1)
+					node.recount()
+					tree.counters.innerMerges++
+					if tree.observer != nil {
+						tree.observer.OnMerge(MergeEvent{Level: level, Merged: nodeRange(node)})
+					}
 					/* trace upwards */
-					tree.nonLeafRemove(parent, i+1)
+					tree.nonLeafRemove(parent, i+1, level+1)
 				}
 			}
 		} else {
@@ -756,6 +1043,9 @@ func (tree *BPlusTree) nonLeafRemove(node *bplusNonLeaf, remove int) {
 				tree.root = node.subPtr[0]
 				node.delete()
 				tree.level--
+				if tree.observer != nil {
+					tree.observer.OnRootShrink(tree.level)
+				}
 			} else {
 				node.simpleRemove(remove)
 			}
@@ -766,10 +1056,26 @@ func (tree *BPlusTree) nonLeafRemove(node *bplusNonLeaf, remove int) {
 }
 
 func (tree *BPlusTree) Delete(key KeyType) int {
+	if tree.multi {
+		/* the first of the equal keys */
+		leaf, i := tree.seekFirst(key)
+		if leaf == nil || leaf.kvs[i].key != key {
+			return -1
+		}
+		tree.leafRemove(leaf, i)
+		return 0
+	}
+
 	node := tree.root
 	for node != nil {
 		if ln, ok := node.(*bplusLeaf); ok {
-			return tree.leafRemove(ln, key)
+			remove, found := ln.keySearch(key)
+			if !found {
+				/* Not exist */
+				return -1
+			}
+			tree.leafRemove(ln, remove)
+			return 0
 		} else {
 			nln := node.(*bplusNonLeaf)
 			i, found := nln.keySearch(key)
@@ -784,11 +1090,15 @@ func (tree *BPlusTree) Delete(key KeyType) int {
 }
 
 func (tree *BPlusTree) listIsLastLeaf(link *bplusLeaf) bool {
-	return link == tree.firstLeaf
+	return link.next == tree.firstLeaf
 }
 
+// GetRange returns the value of the greatest key between key1 and key2
+// inclusive, and whether any key lies in that range. Expired keys are
+// passed over.
 func (tree *BPlusTree) GetRange(key1 KeyType, key2 KeyType) (DataType, bool) {
 	var data DataType
+	var found bool
 	var min, max KeyType
 	if key1 <= key2 {
 		min = key1
@@ -797,133 +1107,13 @@ func (tree *BPlusTree) GetRange(key1 KeyType, key2 KeyType) (DataType, bool) {
 		min = key2
 		max = key1
 	}
-	node := tree.root
-	for node != nil {
-		if ln, ok := node.(*bplusLeaf); ok {
-			i, found := ln.keySearch(min)
-			if !found {
-				if i >= ln.entries {
-					if tree.listIsLastLeaf(ln) {
-						return data, false
-					}
-					ln = ln.next
-				}
-			}
-			for ln.kvs[i].key <= max {
-				data = ln.kvs[i].value
-				i++
-				if i >= ln.entries {
-					if tree.listIsLastLeaf(ln) {
-						return data, false
-					}
-					ln = ln.prev
-					i = 0
-				}
-			}
-			break
-		} else {
-			nln := node.(*bplusNonLeaf)
-			i, found := nln.keySearch(min)
-			if found {
-				node = nln.subPtr[i+1]
-			} else {
-				node = nln.subPtr[i]
-			}
-		}
-	}
-
-	return data, true
-}
-
-func Dump(tree *BPlusTree) {
-	type nodeBacklog struct {
-		/* Node backlogged */
-		node node
-		/* The index next to the backtrack point, must be >= 1 */
-		nextSubIdx int
-	}
-
-	const maxLevel = 20
-
-	var level = 0
-	var nbl *nodeBacklog
-	var nblStack [maxLevel]nodeBacklog
-	var topIndex int
-	top := &nblStack[topIndex]
-
-	node := tree.root
-	for {
-		if node != nil {
-			/* non-zero needs backward and zero does not */
-			var subIdx int
-			if nbl != nil {
-				subIdx = nbl.nextSubIdx
-			}
-			/* Reset each loop */
-			nbl = nil
-
-			/* Backlog the path */
-			nl, ok := node.(*bplusNonLeaf)
-			if !ok || subIdx+1 >= nl.children { // leaf or no children
-				top.node = nil
-				top.nextSubIdx = 0
-			} else {
-				top.node = node
-				top.nextSubIdx = subIdx + 1
-			}
-			topIndex++
-			top = &nblStack[topIndex]
-
-			level++
-
-			/* Draw the whole node when the first entry is passed through */
-			if subIdx == 0 {
-				for i := 1; i < level; i++ {
-					if i == level-1 {
-						fmt.Printf("%-8s", "+-------")
-					} else {
-						if nblStack[i-1].node != nil {
-							fmt.Printf("%-8s", "|")
-						} else {
-							fmt.Printf("%-8s", " ")
-						}
-					}
-				}
-				if leaf, ok := node.(*bplusLeaf); ok {
-					fmt.Printf("leaf:")
-					for i := 0; i < leaf.entries; i++ {
-						fmt.Printf(" %d", leaf.kvs[i].key)
-					}
-				} else {
-					fmt.Printf("node:")
-					nonLeaf := node.(*bplusNonLeaf)
-					for i := 0; i < nonLeaf.children-1; i++ {
-						fmt.Printf(" %d", nonLeaf.key[i])
-					}
-				}
-				println()
-			}
-
-			/* Move deep down */
-			if nln, ok := node.(*bplusNonLeaf); ok {
-				node = nln.subPtr[subIdx]
-			} else {
-				node = nil
-			}
-		} else {
-			if topIndex == 0 {
-				nbl = nil
-			} else {
-				topIndex--
-				top = &nblStack[topIndex]
-				nbl = top
-			}
-			if nbl == nil {
-				/* End of traversal */
-				break
-			}
-			node = nbl.node
-			level--
+	leaf, i := tree.seekFirst(min)
+	for leaf != nil && leaf.kvs[i].key <= max {
+		if !tree.expired(leaf.kvs[i].key) {
+			data = leaf.kvs[i].value
+			found = true
 		}
+		leaf, i = tree.nextPos(leaf, i)
 	}
+	return data, found
 }
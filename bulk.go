@@ -0,0 +1,111 @@
+package bplustree
+
+import (
+	"fmt"
+	"iter"
+)
+
+// chunkSizes splits n items into runs of at most capacity, every run but a
+// lone one holding at least least items.
+func chunkSizes(n, capacity, least int) []int {
+	var sizes []int
+	for ; n > capacity; n -= capacity {
+		sizes = append(sizes, capacity)
+	}
+	sizes = append(sizes, n)
+	if k := len(sizes); k > 1 && sizes[k-1] < least {
+		/* share the last two runs, halves of more than capacity reach least */
+		total := sizes[k-2] + sizes[k-1]
+		sizes[k-2], sizes[k-1] = (total+1)/2, total/2
+	}
+	return sizes
+}
+
+// BulkLoad builds a tree from pairs given in ascending key order, filling
+// the nodes level by level instead of inserting the pairs one by one. The
+// tree is created with the given non-leaf order, leaf capacity and options.
+// Every leaf is filled to capacity but the last two, so inserts into a
+// loaded tree split often; it suits trees that are mostly read. It returns
+// an error if the keys are out of order or repeat without Multi. A tree
+// WithLimit is cut back to its limit once loaded.
+func BulkLoad(order int, entries int, pairs iter.Seq2[KeyType, DataType], opts ...Option) (*BPlusTree, error) {
+	tree := New(order, entries, opts...)
+	type kv struct {
+		key   KeyType
+		value DataType
+	}
+	var all []kv
+	for key, value := range pairs {
+		if n := len(all); n > 0 {
+			if prev := all[n-1].key; key < prev || key == prev && !tree.multi {
+				return nil, fmt.Errorf("bplustree: bulk load key %d after %d", key, prev)
+			}
+		}
+		all = append(all, kv{key, value})
+	}
+	if len(all) == 0 {
+		return tree, nil
+	}
+	tree.count = len(all)
+	for _, p := range all {
+		if tree.changes != nil {
+			tree.changes.add(ChangeInsert, p.key, 0, p.value)
+		}
+		tree.used(p.key)
+	}
+
+	/* leaves, linked into the leaf ring */
+	var level []node
+	var firstKeys []KeyType
+	var prevLeaf *bplusLeaf
+	for _, size := range chunkSizes(len(all), entries, (entries+1)/2) {
+		leaf := leafNew()
+		for i := 0; i < size; i++ {
+			leaf.kvs[i].key, leaf.kvs[i].value = all[i].key, all[i].value
+		}
+		leaf.entries = size
+		all = all[size:]
+		if prevLeaf == nil {
+			tree.firstLeaf = leaf
+		} else {
+			prevLeaf.listAdd(leaf, tree.firstLeaf)
+		}
+		prevLeaf = leaf
+		level = append(level, node(leaf))
+		firstKeys = append(firstKeys, leaf.kvs[0].key)
+	}
+
+	/* non-leaf levels, each linked into its own ring, until one node is left */
+	for len(level) > 1 {
+		var parents []node
+		var parentKeys []KeyType
+		var prevNode *bplusNonLeaf
+		for _, size := range chunkSizes(len(level), order, (order+1)/2) {
+			nl := nonLeafNew()
+			for i := 0; i < size; i++ {
+				nl.subPtr[i] = level[i]
+				bn := getNode(level[i])
+				bn.parent = nl
+				bn.parentKeyIdx = i - 1
+				if i > 0 {
+					nl.key[i-1] = firstKeys[i]
+				}
+			}
+			nl.children = size
+			nl.recount()
+			parentKeys = append(parentKeys, firstKeys[0])
+			level, firstKeys = level[size:], firstKeys[size:]
+			if prevNode != nil {
+				prevNode.listAdd(nl, prevNode.next)
+			}
+			prevNode = nl
+			parents = append(parents, node(nl))
+		}
+		level, firstKeys = parents, parentKeys
+		tree.level++
+	}
+	tree.root = level[0]
+	tree.version++
+	tree.evict()
+	return tree, nil
+}
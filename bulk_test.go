@@ -0,0 +1,57 @@
+package bplustree
+
+import (
+	"testing"
+)
+
+func TestBulkLoad(t *testing.T) {
+	for _, cfg := range [][2]int{{3, 3}, {4, 4}, {5, 7}, {MaxOrder, MaxEntries}} {
+		for _, n := range []int{0, 1, 2, 3, 4, 5, 7, 8, 9, 20, 100, 1000, 5000} {
+			tree, err := BulkLoad(cfg[0], cfg[1], func(yield func(KeyType, DataType) bool) {
+				for i := 0; i < n; i++ {
+					if !yield(i*2, i) {
+						return
+					}
+				}
+			}, WithMerkle())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tree.Verify(); err != nil {
+				t.Fatalf("%v n=%d: %v", cfg, n, err)
+			}
+			if tree.Len() != n {
+				t.Fatalf("%v n=%d: Len() = %d", cfg, n, tree.Len())
+			}
+			/* a loaded tree keeps working as a normal one */
+			for i := 0; i < n; i++ {
+				if v, ok := tree.Search(i * 2); !ok || v != i {
+					t.Fatalf("%v n=%d: Search(%d) = %d, %v", cfg, n, i*2, v, ok)
+				}
+				tree.Insert(i*2+1, i)
+				if i%3 == 0 {
+					tree.Delete(i * 2)
+				}
+			}
+			if err := tree.Verify(); err != nil {
+				t.Fatalf("%v n=%d after updates: %v", cfg, n, err)
+			}
+		}
+	}
+
+	unordered := func(yield func(KeyType, DataType) bool) {
+		_ = yield(2, 0) && yield(1, 0)
+	}
+	if _, err := BulkLoad(4, 4, unordered); err == nil {
+		t.Fatal("BulkLoad accepted keys out of order")
+	}
+	repeated := func(yield func(KeyType, DataType) bool) {
+		_ = yield(1, 0) && yield(1, 1)
+	}
+	if _, err := BulkLoad(4, 4, repeated); err == nil {
+		t.Fatal("BulkLoad accepted a repeated key")
+	}
+	if tree, err := BulkLoad(3, 3, repeated, Multi()); err != nil || tree.Count(1) != 2 {
+		t.Fatalf("multi BulkLoad = %v", err)
+	}
+}
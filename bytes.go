@@ -0,0 +1,685 @@
+package bplustree
+
+import (
+	"bytes"
+)
+
+// BytesTree is a B+ tree keyed by variable-length byte slices. String keys
+// are stored by converting them to []byte.
+//
+// Leaves keep their keys in a compact buffer: the prefix shared by every key
+// of the leaf is stored once and only the remaining suffixes are packed back
+// to back. Non-leaf nodes only keep the shortest separator that still
+// divides two leaves. Nodes are bounded by a page size in bytes rather than
+// by a number of entries, so shorter keys mean more entries per node and a
+// shallower tree.
+//
+// BytesTree has its own node code, separate from BPlusTree, since the
+// nodes of BPlusTree hold fixed-size integer keys in arrays bounded by an
+// entry count. It has its own Verify and iterator; DumpTo, Stats,
+// observers, transactions, batches and the BPlusTree options do not apply
+// to it.
+type BytesTree struct {
+	/** bytes a node may take, see bytesLeaf.size and bytesNonLeaf.size */
+	pageSize int
+	/** height of the tree */
+	level int
+	root  bytesNode
+	/** number of key-value pairs stored in the tree */
+	count int
+	/** bumped by every insert and removal, for iterators */
+	mods uint64
+
+	firstLeaf *bytesLeaf
+}
+
+type bytesNodeHeader struct {
+	parentKeyIdx int           // index of parent node
+	parent       *bytesNonLeaf // pointer to parent node
+}
+
+type bytesNode interface{}
+
+const (
+	/** bytes charged for the offset and value of a leaf entry, or for the pointer to a child */
+	bytesSlot = 16
+	/** the smallest page size, which leaves room for three children of the longest keys */
+	minPageSize = 128
+)
+
+func getBytesNode(n bytesNode) *bytesNodeHeader {
+	if v, ok := n.(*bytesLeaf); ok {
+		return &v.bytesNodeHeader
+	} else {
+		return &n.(*bytesNonLeaf).bytesNodeHeader
+	}
+}
+
+type bytesNonLeaf struct {
+	bytesNodeHeader
+	/** separator keys, len(key) == len(subPtr) - 1 */
+	key [][]byte
+	/** pointers to child node */
+	subPtr []bytesNode
+}
+
+// size returns the bytes the node takes against the page size.
+func (nl *bytesNonLeaf) size() int {
+	n := len(nl.subPtr) * bytesSlot
+	for _, k := range nl.key {
+		n += len(k)
+	}
+	return n
+}
+
+// splitPoint returns how many children to keep when splitting the node so
+// that the larger half is the smallest. Each half keeps two children at
+// least.
+func (nl *bytesNonLeaf) splitPoint() int {
+	total := nl.size()
+	best, split := -1, 2
+	left := bytesSlot
+	for j := 2; j <= len(nl.subPtr)-2; j++ {
+		left += len(nl.key[j-2]) + bytesSlot
+		right := total - left - len(nl.key[j-1])
+		if m := max(left, right); best < 0 || m < best {
+			best, split = m, j
+		}
+	}
+	return split
+}
+
+func (nl *bytesNonLeaf) keySearch(target []byte) int {
+	i, j := 0, len(nl.key)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if bytes.Compare(nl.key[h], target) <= 0 {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+	return i
+}
+
+func (nl *bytesNonLeaf) reindex(from int) {
+	for i := from; i < len(nl.subPtr); i++ {
+		bn := getBytesNode(nl.subPtr[i])
+		bn.parent = nl
+		bn.parentKeyIdx = i - 1
+	}
+}
+
+func (nl *bytesNonLeaf) simpleInsert(rch bytesNode, key []byte, insert int) {
+	nl.key = append(nl.key, nil)
+	copy(nl.key[insert+1:], nl.key[insert:])
+	nl.key[insert] = key
+	nl.subPtr = append(nl.subPtr, nil)
+	copy(nl.subPtr[insert+2:], nl.subPtr[insert+1:])
+	nl.subPtr[insert+1] = rch
+	nl.reindex(insert + 1)
+}
+
+func (nl *bytesNonLeaf) simpleRemove(remove int) {
+	copy(nl.key[remove:], nl.key[remove+1:])
+	nl.key[len(nl.key)-1] = nil
+	nl.key = nl.key[:len(nl.key)-1]
+	copy(nl.subPtr[remove+1:], nl.subPtr[remove+2:])
+	// for gc
+	nl.subPtr[len(nl.subPtr)-1] = nil
+	nl.subPtr = nl.subPtr[:len(nl.subPtr)-1]
+	nl.reindex(remove + 1)
+}
+
+func (nl *bytesNonLeaf) shiftFromLeft(left *bytesNonLeaf, parentKeyIndex int) {
+	/* parent key right rotation */
+	nl.key = append(nl.key, nil)
+	copy(nl.key[1:], nl.key)
+	nl.key[0] = nl.parent.key[parentKeyIndex]
+	nl.parent.key[parentKeyIndex] = left.key[len(left.key)-1]
+	left.key = left.key[:len(left.key)-1]
+	/* borrow the last sub-node from left sibling */
+	nl.subPtr = append(nl.subPtr, nil)
+	copy(nl.subPtr[1:], nl.subPtr)
+	nl.subPtr[0] = left.subPtr[len(left.subPtr)-1]
+	left.subPtr[len(left.subPtr)-1] = nil
+	left.subPtr = left.subPtr[:len(left.subPtr)-1]
+	nl.reindex(0)
+}
+
+func (nl *bytesNonLeaf) shiftFromRight(right *bytesNonLeaf, parentKeyIndex int) {
+	/* parent key left rotation */
+	nl.key = append(nl.key, nl.parent.key[parentKeyIndex])
+	nl.parent.key[parentKeyIndex] = right.key[0]
+	/* borrow the first sub-node from right sibling */
+	nl.subPtr = append(nl.subPtr, right.subPtr[0])
+	nl.reindex(len(nl.subPtr) - 1)
+	/* left shift in right sibling */
+	right.key = append(right.key[:0], right.key[1:]...)
+	right.subPtr = append(right.subPtr[:0], right.subPtr[1:]...)
+	right.reindex(0)
+}
+
+func (nl *bytesNonLeaf) mergeFromRight(right *bytesNonLeaf, parentKeyIndex int) {
+	/* move parent key down and append the right sibling */
+	nl.key = append(nl.key, nl.parent.key[parentKeyIndex])
+	nl.key = append(nl.key, right.key...)
+	from := len(nl.subPtr)
+	nl.subPtr = append(nl.subPtr, right.subPtr...)
+	nl.reindex(from)
+}
+
+type bytesLeaf struct {
+	bytesNodeHeader
+	prev, next *bytesLeaf
+	/** prefix shared by every key in the leaf */
+	prefix []byte
+	/** key suffixes packed back to back, suffix i is buf[off[i]:off[i+1]] */
+	buf []byte
+	off []int
+	/** values, one per key */
+	values []DataType
+}
+
+// size returns the bytes the leaf takes against the page size.
+func (leaf *bytesLeaf) size() int {
+	return len(leaf.prefix) + len(leaf.buf) + len(leaf.values)*bytesSlot
+}
+
+// mergedSize returns an upper bound of the size of the leaf after
+// mergeFromRight(right).
+func (leaf *bytesLeaf) mergedSize(right *bytesLeaf) int {
+	p := commonPrefixLen(leaf.prefix, right.prefix)
+	return p + len(leaf.buf) + (len(leaf.prefix)-p)*len(leaf.values) +
+		len(right.buf) + (len(right.prefix)-p)*len(right.values) +
+		(len(leaf.values)+len(right.values))*bytesSlot
+}
+
+func (leaf *bytesLeaf) suffix(i int) []byte {
+	return leaf.buf[leaf.off[i]:leaf.off[i+1]]
+}
+
+func (leaf *bytesLeaf) key(i int) []byte {
+	s := leaf.suffix(i)
+	k := make([]byte, len(leaf.prefix)+len(s))
+	copy(k, leaf.prefix)
+	copy(k[len(leaf.prefix):], s)
+	return k
+}
+
+func (leaf *bytesLeaf) keySearch(target []byte) (int, bool) {
+	n := len(leaf.prefix)
+	if len(target) < n || !bytes.Equal(target[:n], leaf.prefix) {
+		/* every key of the leaf starts with the prefix */
+		if bytes.Compare(target, leaf.prefix) < 0 {
+			return 0, false
+		}
+		return len(leaf.values), false
+	}
+
+	t := target[n:]
+	i, j := 0, len(leaf.values)
+	for i < j {
+		h := int(uint(i+j) >> 1)
+		if bytes.Compare(leaf.suffix(h), t) <= 0 {
+			i = h + 1
+		} else {
+			j = h
+		}
+	}
+
+	if i > 0 && bytes.Equal(leaf.suffix(i-1), t) {
+		return i - 1, true
+	}
+	return i, false
+}
+
+func (leaf *bytesLeaf) listAdd(link *bytesLeaf, next *bytesLeaf) {
+	link.next = next
+	link.prev = leaf
+	next.prev = link
+	leaf.next = link
+}
+
+func (leaf *bytesLeaf) delete() {
+	leaf.prev.next = leaf.next
+	leaf.next.prev = leaf.prev
+}
+
+// shrinkPrefix moves prefix[n:] back into every suffix.
+func (leaf *bytesLeaf) shrinkPrefix(n int) {
+	tail := leaf.prefix[n:]
+	buf := make([]byte, 0, len(leaf.buf)+len(leaf.values)*len(tail))
+	for i := range leaf.values {
+		s := leaf.suffix(i)
+		leaf.off[i] = len(buf)
+		buf = append(buf, tail...)
+		buf = append(buf, s...)
+	}
+	leaf.off[len(leaf.values)] = len(buf)
+	leaf.buf = buf
+	leaf.prefix = leaf.prefix[:n]
+}
+
+func (leaf *bytesLeaf) simpleInsert(key []byte, data DataType, insert int) {
+	if len(leaf.values) == 0 {
+		leaf.prefix = append(leaf.prefix[:0], key...)
+	} else if !bytes.HasPrefix(key, leaf.prefix) {
+		leaf.shrinkPrefix(commonPrefixLen(leaf.prefix, key))
+	}
+
+	s := key[len(leaf.prefix):]
+	at := leaf.off[insert]
+	leaf.buf = append(leaf.buf, s...)
+	copy(leaf.buf[at+len(s):], leaf.buf[at:len(leaf.buf)-len(s)])
+	copy(leaf.buf[at:], s)
+
+	leaf.off = append(leaf.off, 0)
+	for j := len(leaf.off) - 1; j > insert; j-- {
+		leaf.off[j] = leaf.off[j-1] + len(s)
+	}
+
+	leaf.values = append(leaf.values, 0)
+	copy(leaf.values[insert+1:], leaf.values[insert:])
+	leaf.values[insert] = data
+}
+
+func (leaf *bytesLeaf) simpleRemove(remove int) {
+	l := leaf.off[remove+1] - leaf.off[remove]
+	copy(leaf.buf[leaf.off[remove]:], leaf.buf[leaf.off[remove+1]:])
+	leaf.buf = leaf.buf[:len(leaf.buf)-l]
+
+	for j := remove; j < len(leaf.off)-1; j++ {
+		leaf.off[j] = leaf.off[j+1] - l
+	}
+	leaf.off = leaf.off[:len(leaf.off)-1]
+
+	copy(leaf.values[remove:], leaf.values[remove+1:])
+	leaf.values = leaf.values[:len(leaf.values)-1]
+	if len(leaf.values) == 0 {
+		leaf.prefix = leaf.prefix[:0]
+	}
+}
+
+// reset re-encodes the leaf from sorted keys, truncating the longest
+// prefix they share.
+func (leaf *bytesLeaf) reset(keys [][]byte, values []DataType) {
+	n := commonPrefixLen(keys[0], keys[len(keys)-1])
+	leaf.prefix = append(leaf.prefix[:0], keys[0][:n]...)
+	leaf.buf = leaf.buf[:0]
+	leaf.off = append(leaf.off[:0], 0)
+	for _, k := range keys {
+		leaf.buf = append(leaf.buf, k[n:]...)
+		leaf.off = append(leaf.off, len(leaf.buf))
+	}
+	leaf.values = append(leaf.values[:0], values...)
+}
+
+func (leaf *bytesLeaf) shiftFromLeft(left *bytesLeaf, parentKeyIndex int) {
+	/* borrow the last element from left sibling */
+	last := len(left.values) - 1
+	leaf.simpleInsert(left.key(last), left.values[last], 0)
+	left.simpleRemove(last)
+	/* update parent key */
+	leaf.parent.key[parentKeyIndex] = shortestSeparator(left.key(last-1), leaf.key(0))
+}
+
+func (leaf *bytesLeaf) shiftFromRight(right *bytesLeaf, parentKeyIndex int) {
+	/* borrow the first element from right sibling */
+	leaf.simpleInsert(right.key(0), right.values[0], len(leaf.values))
+	right.simpleRemove(0)
+	/* update parent key */
+	leaf.parent.key[parentKeyIndex] = shortestSeparator(leaf.key(len(leaf.values)-1), right.key(0))
+}
+
+func (leaf *bytesLeaf) mergeFromRight(right *bytesLeaf) {
+	/* merge from right sibling */
+	for i := range right.values {
+		leaf.simpleInsert(right.key(i), right.values[i], len(leaf.values))
+	}
+	/* delete right sibling */
+	right.delete()
+}
+
+func commonPrefixLen(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// leafSplitPoint returns where to split sorted keys into two leaves so that
+// the larger leaf is the smallest.
+func leafSplitPoint(keys [][]byte) int {
+	sum := make([]int, len(keys)+1)
+	for i, k := range keys {
+		sum[i+1] = sum[i] + len(k) + bytesSlot
+	}
+	/* the size of a leaf reset from keys[from:to] */
+	size := func(from, to int) int {
+		return sum[to] - sum[from] - (to-from-1)*commonPrefixLen(keys[from], keys[to-1])
+	}
+	best, split := -1, 1
+	for i := 1; i < len(keys); i++ {
+		if m := max(size(0, i), size(i, len(keys))); best < 0 || m < best {
+			best, split = m, i
+		}
+	}
+	return split
+}
+
+// shortestSeparator returns the shortest key s with a < s <= b.
+func shortestSeparator(a, b []byte) []byte {
+	n := commonPrefixLen(a, b) + 1
+	s := make([]byte, n)
+	copy(s, b[:n])
+	return s
+}
+
+// NewBytes returns an empty BytesTree whose nodes take at most pageSize
+// bytes each, counting the key bytes a node stores and a 16-byte slot per
+// entry or child. pageSize must be at least 128. Keys longer than a quarter
+// of pageSize are rejected by Insert.
+func NewBytes(pageSize int) *BytesTree {
+	assert(pageSize >= minPageSize)
+
+	tree := new(BytesTree)
+	tree.pageSize = pageSize
+	return tree
+}
+
+func (tree *BytesTree) leafNew() *bytesLeaf {
+	leaf := new(bytesLeaf)
+	leaf.prev = leaf
+	leaf.next = leaf
+	leaf.parentKeyIdx = -1
+	leaf.off = []int{0}
+	return leaf
+}
+
+func (tree *BytesTree) nonLeafNew() *bytesNonLeaf {
+	nonLeaf := new(bytesNonLeaf)
+	nonLeaf.parentKeyIdx = -1
+	return nonLeaf
+}
+
+// minBytes is the size below which a node other than the root takes
+// entries from a sibling or merges with it.
+func (tree *BytesTree) minBytes() int {
+	return tree.pageSize / 4
+}
+
+// maxKeyLen is the length of the longest key, which leaves room for three
+// children in a non-leaf node and for a leaf split to divide its entries.
+func (tree *BytesTree) maxKeyLen() int {
+	return tree.pageSize / 4
+}
+
+func (tree *BytesTree) parentNodeBuild(left bytesNode, right bytesNode, key []byte) {
+	ln := getBytesNode(left)
+	if ln.parent == nil {
+		/* new parent */
+		parent := tree.nonLeafNew()
+		parent.key = append(parent.key, key)
+		parent.subPtr = append(parent.subPtr, left, right)
+		parent.reindex(0)
+		/* update root */
+		tree.root = parent
+		tree.level++
+		return
+	}
+	/* trace upwards */
+	tree.nonLeafInsert(ln.parent, right, key, ln.parentKeyIdx+1)
+}
+
+func (tree *BytesTree) nonLeafInsert(node *bytesNonLeaf, rCh bytesNode, key []byte, insert int) {
+	node.simpleInsert(rCh, key, insert)
+	tree.nonLeafSplit(node)
+}
+
+// nonLeafSplit splits node in two if it is over the page size.
+func (tree *BytesTree) nonLeafSplit(node *bytesNonLeaf) {
+	if node.size() <= tree.pageSize {
+		return
+	}
+
+	/* node overflow, split by size */
+	split := node.splitPoint()
+	sibling := tree.nonLeafNew()
+	splitKey := node.key[split-1]
+	sibling.key = append(sibling.key, node.key[split:]...)
+	sibling.subPtr = append(sibling.subPtr, node.subPtr[split:]...)
+	sibling.reindex(0)
+	for i := split; i < len(node.subPtr); i++ {
+		// for gc
+		node.subPtr[i] = nil
+	}
+	node.key = node.key[:split-1]
+	node.subPtr = node.subPtr[:split]
+	/* build new parent */
+	tree.parentNodeBuild(node, sibling, splitKey)
+}
+
+func (tree *BytesTree) leafInsert(leaf *bytesLeaf, key []byte, data DataType) int {
+	/* search key location */
+	insert, ok := leaf.keySearch(key)
+	if ok {
+		/* Already exists */
+		return -1
+	}
+
+	tree.count++
+	tree.mods++
+	leaf.simpleInsert(key, data, insert)
+	tree.leafSplit(leaf)
+	return 0
+}
+
+// leafSplit splits leaf until every part of it is within the page size.
+func (tree *BytesTree) leafSplit(leaf *bytesLeaf) {
+	if leaf.size() <= tree.pageSize || len(leaf.values) < 2 {
+		return
+	}
+
+	/* node overflow, gather every key to re-encode both halves */
+	keys := make([][]byte, len(leaf.values))
+	for i := range keys {
+		keys[i] = leaf.key(i)
+	}
+	values := append([]DataType(nil), leaf.values...)
+
+	split := leafSplitPoint(keys)
+	sibling := tree.leafNew()
+	leaf.reset(keys[:split], values[:split])
+	sibling.reset(keys[split:], values[split:])
+	leaf.listAdd(sibling, leaf.next)
+
+	/* build new parent */
+	tree.parentNodeBuild(leaf, sibling, shortestSeparator(keys[split-1], keys[split]))
+	/* a half whose keys share less of a prefix may still be over */
+	tree.leafSplit(leaf)
+	tree.leafSplit(sibling)
+}
+
+func (tree *BytesTree) leafRemove(leaf *bytesLeaf, remove int) {
+	tree.count--
+	tree.mods++
+	leaf.simpleRemove(remove)
+
+	parent := leaf.parent
+	if parent == nil {
+		if len(leaf.values) == 0 {
+			/* delete the only last node */
+			tree.root = nil
+			tree.firstLeaf = nil
+		}
+		return
+	}
+	if leaf.size() >= tree.minBytes() {
+		return
+	}
+
+	/* decide which sibling to be merged with or borrowed from */
+	i := leaf.parentKeyIdx
+	if bytesSiblingSelect(parent, i) {
+		lSib := leaf.prev
+		if lSib.mergedSize(leaf) <= tree.pageSize {
+			lSib.mergeFromRight(leaf)
+			/* trace upwards */
+			tree.nonLeafRemove(parent, i)
+			return
+		}
+		for leaf.size() < tree.minBytes() && lSib.size() > leaf.size() && len(lSib.values) > 1 {
+			leaf.shiftFromLeft(lSib, i)
+		}
+	} else {
+		rSib := leaf.next
+		if leaf.mergedSize(rSib) <= tree.pageSize {
+			leaf.mergeFromRight(rSib)
+			/* trace upwards */
+			tree.nonLeafRemove(parent, i+1)
+			return
+		}
+		for leaf.size() < tree.minBytes() && rSib.size() > leaf.size() && len(rSib.values) > 1 {
+			leaf.shiftFromRight(rSib, i+1)
+		}
+	}
+	/* a borrowed key may widen the leaf past the page, a new separator the parent */
+	tree.leafSplit(leaf)
+	tree.nonLeafSplit(parent)
+}
+
+func (tree *BytesTree) nonLeafRemove(node *bytesNonLeaf, remove int) {
+	node.simpleRemove(remove)
+
+	parent := node.parent
+	if parent == nil {
+		if len(node.subPtr) == 1 {
+			/* delete old root node */
+			sbn := getBytesNode(node.subPtr[0])
+			sbn.parent = nil
+			sbn.parentKeyIdx = -1
+			tree.root = node.subPtr[0]
+			tree.level--
+		}
+		return
+	}
+	if node.size() >= tree.minBytes() {
+		return
+	}
+
+	/* decide which sibling to be merged with or borrowed from */
+	i := node.parentKeyIdx
+	if bytesSiblingSelect(parent, i) {
+		lSib := parent.subPtr[i].(*bytesNonLeaf)
+		if lSib.size()+len(parent.key[i])+node.size() <= tree.pageSize {
+			lSib.mergeFromRight(node, i)
+			/* trace upwards */
+			tree.nonLeafRemove(parent, i)
+			return
+		}
+		for node.size() < tree.minBytes() && lSib.size() > node.size() && len(lSib.subPtr) > 2 {
+			node.shiftFromLeft(lSib, i)
+		}
+	} else {
+		rSib := parent.subPtr[i+2].(*bytesNonLeaf)
+		if node.size()+len(parent.key[i+1])+rSib.size() <= tree.pageSize {
+			node.mergeFromRight(rSib, i+1)
+			/* trace upwards */
+			tree.nonLeafRemove(parent, i+1)
+			return
+		}
+		for node.size() < tree.minBytes() && rSib.size() > node.size() && len(rSib.subPtr) > 2 {
+			node.shiftFromRight(rSib, i+1)
+		}
+	}
+	/* the separator moved up may be longer than the one it replaced */
+	tree.nonLeafSplit(parent)
+}
+
+func bytesSiblingSelect(parent *bytesNonLeaf, i int) (isLeft bool) {
+	if i == -1 {
+		/* the first sub-node, no left sibling, choose the right one */
+		return false
+	} else if i == len(parent.subPtr)-2 {
+		/* the last sub-node, no right sibling, choose the left one */
+		return true
+	}
+	/* if both left and right sibling found, choose the larger one */
+	return bytesNodeSize(parent.subPtr[i]) >= bytesNodeSize(parent.subPtr[i+2])
+}
+
+func bytesNodeSize(n bytesNode) int {
+	if leaf, ok := n.(*bytesLeaf); ok {
+		return leaf.size()
+	}
+	return n.(*bytesNonLeaf).size()
+}
+
+func (tree *BytesTree) findLeaf(key []byte) *bytesLeaf {
+	node := tree.root
+	for node != nil {
+		if ln, ok := node.(*bytesLeaf); ok {
+			return ln
+		}
+		nln := node.(*bytesNonLeaf)
+		node = nln.subPtr[nln.keySearch(key)]
+	}
+	return nil
+}
+
+func (tree *BytesTree) Insert(key []byte, data DataType) int {
+	if len(key) > tree.maxKeyLen() {
+		return -1
+	}
+	leaf := tree.findLeaf(key)
+	if leaf != nil {
+		return tree.leafInsert(leaf, key, data)
+	}
+
+	/* new root */
+	root := tree.leafNew()
+	root.simpleInsert(key, data, 0)
+	tree.root = root
+	tree.count = 1
+	tree.mods++
+
+	tree.firstLeaf = root
+	return 0
+}
+
+// Len returns the number of key-value pairs stored in the tree.
+func (tree *BytesTree) Len() int {
+	return tree.count
+}
+
+func (tree *BytesTree) Search(key []byte) (ret DataType, ok bool) {
+	leaf := tree.findLeaf(key)
+	if leaf != nil {
+		var i int
+		if i, ok = leaf.keySearch(key); ok {
+			ret = leaf.values[i]
+		}
+	}
+	return
+}
+
+func (tree *BytesTree) Delete(key []byte) int {
+	leaf := tree.findLeaf(key)
+	if leaf == nil {
+		return -1
+	}
+	remove, ok := leaf.keySearch(key)
+	if !ok {
+		/* Not exist */
+		return -1
+	}
+	tree.leafRemove(leaf, remove)
+	return 0
+}
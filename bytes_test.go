@@ -0,0 +1,161 @@
+package bplustree
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBytesTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, cfg := range []int{128, 160, 256, 1024} {
+		tree := NewBytes(cfg)
+		model := make(map[string]DataType)
+		for i := 0; i < 3000; i++ {
+			key := fmt.Sprintf("/tenant%d/user/%d", r.Intn(4), r.Intn(200))
+			if r.Intn(3) == 0 {
+				_, ok := model[key]
+				if got := tree.Delete([]byte(key)); (got == 0) != ok {
+					t.Fatalf("%v: Delete(%q) = %d, in model %v", cfg, key, got, ok)
+				}
+				delete(model, key)
+			} else {
+				_, ok := model[key]
+				if got := tree.Insert([]byte(key), i); (got == 0) == ok {
+					t.Fatalf("%v: Insert(%q) = %d, in model %v", cfg, key, got, ok)
+				}
+				if !ok {
+					model[key] = i
+				}
+			}
+			if i%100 == 0 {
+				if err := tree.Verify(); err != nil {
+					t.Fatalf("%v: %v", cfg, err)
+				}
+			}
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatalf("%v: %v", cfg, err)
+		}
+		if tree.Len() != len(model) {
+			t.Fatalf("%v: Len() = %d, want %d", cfg, tree.Len(), len(model))
+		}
+
+		for k, v := range model {
+			if got, ok := tree.Search([]byte(k)); !ok || got != v {
+				t.Fatalf("%v: Search(%q) = %d, %v, want %d", cfg, k, got, ok, v)
+			}
+		}
+		if _, ok := tree.Search([]byte("/tenant")); ok {
+			t.Fatalf("%v: found key that was never inserted", cfg)
+		}
+
+		want := make([]string, 0, len(model))
+		for k := range model {
+			want = append(want, k)
+		}
+		sort.Strings(want)
+		var got []string
+		for it := tree.First(); it.Valid(); it.Next() {
+			got = append(got, string(it.Key()))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("%v: iterator yields %d keys, want %d", cfg, len(got), len(want))
+		}
+		got = got[:0]
+		for it := tree.Last(); it.Valid(); it.Prev() {
+			got = append(got, string(it.Key()))
+		}
+		slices.Reverse(got)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Fatalf("%v: reverse iterator yields %d keys, want %d", cfg, len(got), len(want))
+		}
+	}
+}
+
+func TestBytesTreeHeight(t *testing.T) {
+	const pageSize, n = 4096, 20000
+	prefix := strings.Repeat("/api/v2/tenants/acme/", 10)
+	tree := NewBytes(pageSize)
+	keyLen := 0
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("%s%06d", prefix, i*7919%n)
+		keyLen = len(key)
+		tree.Insert([]byte(key), i)
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	/* the lowest tree holding whole keys in nodes of the same size */
+	fanout := pageSize / (keyLen + bytesSlot)
+	whole := 1
+	for c := fanout; c < n; c *= fanout {
+		whole++
+	}
+	if tree.level+1 >= whole {
+		t.Fatalf("height %d, a tree of whole keys needs %d", tree.level+1, whole)
+	}
+}
+
+func TestShortestSeparator(t *testing.T) {
+	for _, c := range [][3]string{
+		{"abc", "abd", "abd"},
+		{"ab", "abcdef", "abc"},
+		{"apple", "banana", "b"},
+		{"a\xff", "b", "b"},
+	} {
+		s := shortestSeparator([]byte(c[0]), []byte(c[1]))
+		if string(s) != c[2] {
+			t.Fatalf("shortestSeparator(%q, %q) = %q, want %q", c[0], c[1], s, c[2])
+		}
+		if bytes.Compare([]byte(c[0]), s) >= 0 || bytes.Compare(s, []byte(c[1])) > 0 {
+			t.Fatalf("shortestSeparator(%q, %q) = %q out of range", c[0], c[1], s)
+		}
+	}
+}
+
+func TestBytesIterator(t *testing.T) {
+	tree := NewBytes(128)
+	for i := 0; i < 200; i++ {
+		tree.Insert([]byte(fmt.Sprintf("key%03d", i)), i)
+	}
+	if it := tree.Seek([]byte("key0505")); !it.Valid() || string(it.Key()) != "key051" {
+		t.Fatalf("Seek stops at %q", it.Key())
+	}
+	if it := tree.Seek([]byte("key2")); it.Valid() || it.Key() != nil || it.Value() != 0 {
+		t.Fatal("Seek past the last key is valid")
+	}
+
+	/* deleting the yielded key and the one after it skips neither */
+	var got []int
+	for k, v := range tree.Ascend([]byte("key010"), []byte("key089")) {
+		got = append(got, v)
+		tree.Delete(k)
+		if v%2 == 0 {
+			tree.Delete([]byte(fmt.Sprintf("key%03d", v+1)))
+		}
+	}
+	if len(got) != 40 || got[0] != 10 || got[39] != 88 {
+		t.Fatalf("yielded %v", got)
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 120 {
+		t.Fatalf("Len() = %d", tree.Len())
+	}
+
+	it := tree.Seek([]byte("key095"))
+	tree.Delete([]byte("key095"))
+	if string(it.Key()) != "key096" || it.Value() != 96 {
+		t.Fatalf("repositioned at %q", it.Key())
+	}
+	if it.Prev(); string(it.Key()) != "key094" {
+		t.Fatalf("Prev after a removal moved to %q", it.Key())
+	}
+}
@@ -0,0 +1,152 @@
+package bplustree
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ChangeOp is the kind of mutation a Change records.
+type ChangeOp int
+
+const (
+	ChangeInsert ChangeOp = iota
+	ChangeUpdate
+	ChangeDelete
+)
+
+func (op ChangeOp) String() string {
+	switch op {
+	case ChangeInsert:
+		return "insert"
+	case ChangeUpdate:
+		return "update"
+	case ChangeDelete:
+		return "delete"
+	}
+	return fmt.Sprintf("ChangeOp(%d)", int(op))
+}
+
+// Change is one successful mutation of a tree. Seq numbers start at 1 and
+// grow by one per change.
+type Change struct {
+	Seq uint64
+	Op  ChangeOp
+	Key KeyType
+	/** value before the change, zero for an insert */
+	OldValue DataType
+	/** value after the change, zero for a delete */
+	NewValue DataType
+}
+
+// ErrChangesLost is returned by Changes when records after the requested
+// sequence number have already been dropped from the retention buffer.
+var ErrChangesLost = errors.New("bplustree: changes no longer retained")
+
+// changeLog is a ring of the latest changes.
+type changeLog struct {
+	buf []Change
+	/** seq of the next change */
+	next uint64
+}
+
+func (log *changeLog) add(op ChangeOp, key KeyType, oldValue, newValue DataType) {
+	c := Change{Seq: log.next, Op: op, Key: key, OldValue: oldValue, NewValue: newValue}
+	log.buf[(log.next-1)%uint64(len(log.buf))] = c
+	log.next++
+}
+
+/** seq of the oldest retained change */
+func (log *changeLog) first() uint64 {
+	if n := uint64(len(log.buf)); log.next > n {
+		return log.next - n
+	}
+	return 1
+}
+
+// WithChanges records every successful Insert, Update and Delete of the
+// tree, keeping the latest retention changes for Changes.
+func WithChanges(retention int) Option {
+	assert(retention > 0)
+	return func(tree *BPlusTree) {
+		tree.changes = &changeLog{buf: make([]Change, retention), next: 1}
+	}
+}
+
+// LastSeq returns the seq of the latest change, 0 if there is none or the
+// tree does not record changes.
+func (tree *BPlusTree) LastSeq() uint64 {
+	if tree.changes == nil {
+		return 0
+	}
+	return tree.changes.next - 1
+}
+
+// Changes returns the retained changes after seq since, in order. The
+// iterator stops at the changes present when it started, or early when
+// the tree drops the ones it has not reached yet. It returns
+// ErrChangesLost if some of those changes were already dropped, in which
+// case a replica has to be rebuilt from a copy of the tree.
+func (tree *BPlusTree) Changes(since uint64) (iter.Seq[Change], error) {
+	log := tree.changes
+	if log == nil {
+		return nil, errors.New("bplustree: tree does not record changes")
+	}
+	if since+1 < log.first() {
+		return nil, ErrChangesLost
+	}
+	end := log.next
+	return func(yield func(Change) bool) {
+		for seq := since + 1; seq < end; seq++ {
+			if seq < log.first() {
+				/* overwritten by changes added meanwhile */
+				return
+			}
+			if !yield(log.buf[(seq-1)%uint64(len(log.buf))]) {
+				return
+			}
+		}
+	}, nil
+}
+
+// ApplyChanges applies a change feed to a replica tree and returns the seq
+// of the last change applied, 0 if there was none. It stops at the first
+// change the replica cannot apply, which means the replica has diverged
+// from the source.
+func ApplyChanges(replica *BPlusTree, changes iter.Seq[Change]) (uint64, error) {
+	var last uint64
+	for c := range changes {
+		var ret int
+		switch c.Op {
+		case ChangeInsert:
+			ret = replica.Insert(c.Key, c.NewValue)
+		case ChangeUpdate:
+			ret = replica.Update(c.Key, c.NewValue)
+		case ChangeDelete:
+			if replica.multi {
+				ret = replica.DeleteOne(c.Key, c.OldValue)
+			} else {
+				ret = replica.Delete(c.Key)
+			}
+		}
+		if ret != 0 {
+			return last, fmt.Errorf("bplustree: replica cannot apply %s of key %d at seq %d", c.Op, c.Key, c.Seq)
+		}
+		last = c.Seq
+	}
+	return last, nil
+}
+
+// Replicate brings a replica that has applied the changes of tree up to
+// seq since in sync with tree, and returns the seq it is now at.
+func (tree *BPlusTree) Replicate(replica *BPlusTree, since uint64) (uint64, error) {
+	changes, err := tree.Changes(since)
+	if err != nil {
+		return since, err
+	}
+	last, err := ApplyChanges(replica, changes)
+	if last == 0 {
+		last = since
+	}
+	return last, err
+}
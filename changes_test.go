@@ -0,0 +1,104 @@
+package bplustree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, multi := range []bool{false, true} {
+		opts := []Option{WithChanges(256)}
+		var replicaOpts []Option
+		if multi {
+			opts = append(opts, Multi())
+			replicaOpts = append(replicaOpts, Multi())
+		}
+		src, replica := New(3, 3, opts...), New(4, 4, replicaOpts...)
+		var seq uint64
+		for round := 0; round < 200; round++ {
+			for i := 0; i < r.Intn(40); i++ {
+				key := r.Intn(50)
+				switch r.Intn(5) {
+				case 0:
+					src.Delete(key)
+				case 1:
+					src.Update(key, -i)
+				case 2:
+					src.DeleteMany([]KeyType{key, key + 1})
+				default:
+					src.Insert(key, round*100+i)
+				}
+			}
+			if r.Intn(4) == 0 {
+				/* a rolled back transaction shows up as its writes and their undo */
+				txn := src.Begin()
+				txn.Insert(r.Intn(50), 7)
+				txn.Update(r.Intn(50), 8)
+				txn.Delete(r.Intn(50))
+				txn.Rollback()
+			}
+
+			var err error
+			if seq, err = src.Replicate(replica, seq); err != nil {
+				t.Fatalf("multi %v round %d: %v", multi, round, err)
+			}
+			if seq != src.LastSeq() {
+				t.Fatalf("replica at seq %d, source at %d", seq, src.LastSeq())
+			}
+			if got, want := treeContents(replica, multi), treeContents(src, multi); got != want {
+				t.Fatalf("multi %v round %d: replica\n%s\nsource\n%s", multi, round, got, want)
+			}
+		}
+	}
+}
+
+func TestChangesRetention(t *testing.T) {
+	tree := New(4, 4, WithChanges(4))
+	tree.Insert(1, 10)
+	tree.Update(1, 11)
+	tree.Delete(1)
+	changes, err := tree.Changes(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Seq: 1, Op: ChangeInsert, Key: 1, NewValue: 10},
+		{Seq: 2, Op: ChangeUpdate, Key: 1, OldValue: 10, NewValue: 11},
+		{Seq: 3, Op: ChangeDelete, Key: 1, OldValue: 11},
+	}
+	var got []Change
+	for c := range changes {
+		got = append(got, c)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("change %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		tree.Insert(i, i)
+	}
+	if _, err := tree.Changes(1); err != ErrChangesLost {
+		t.Fatalf("Changes(1) = %v, want ErrChangesLost", err)
+	}
+	if _, err := tree.Changes(2); err != nil {
+		t.Fatalf("Changes(2) = %v", err)
+	}
+	/* an iterator overtaken by new changes stops instead of skipping */
+	changes, _ = tree.Changes(2)
+	n := 0
+	for range changes {
+		if n++; n == 1 {
+			tree.Insert(10, 10)
+			tree.Insert(11, 11)
+		}
+	}
+	if n != 1 {
+		t.Fatalf("overtaken iterator yielded %d changes", n)
+	}
+}
@@ -222,7 +222,7 @@ func commandProcess(tree *bplustree.BPlusTree) {
 		case 'h':
 			command_tips()
 		case 'd':
-			bplustree.Dump(tree)
+			_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 		case 'i', 'r', 's':
 			if numberProcess(br, tree, c) < 0 {
 				return
@@ -0,0 +1,157 @@
+package bplustree
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DumpFormat selects the output format of DumpTo.
+type DumpFormat int
+
+const (
+	// DumpText draws the tree as indented ASCII art, one node per line.
+	DumpText DumpFormat = iota
+	// DumpDOT writes a Graphviz digraph with child and leaf sibling edges.
+	DumpDOT
+	// DumpJSON writes the tree as a nested JSON document.
+	DumpJSON
+)
+
+// Dump draws the tree structure to stdout.
+func Dump(tree *BPlusTree) {
+	_ = tree.DumpTo(os.Stdout, DumpText)
+}
+
+// DumpTo writes the tree structure to w in the given format.
+func (tree *BPlusTree) DumpTo(w io.Writer, format DumpFormat) error {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case DumpText:
+		if tree.root != nil {
+			dumpText(bw, tree.root, nil)
+		}
+	case DumpDOT:
+		dumpDOT(bw, tree)
+	case DumpJSON:
+		if err := json.NewEncoder(bw).Encode(dumpJSONTree(tree)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("bplustree: unknown dump format %d", format)
+	}
+	return bw.Flush()
+}
+
+// dumpText draws n and its subtree. more[d] tells whether the ancestor at
+// depth d has children left to draw below the current path.
+func dumpText(w *bufio.Writer, n node, more []bool) {
+	for d := range more {
+		if d == len(more)-1 {
+			fmt.Fprintf(w, "%-8s", "+-------")
+		} else if more[d] {
+			fmt.Fprintf(w, "%-8s", "|")
+		} else {
+			fmt.Fprintf(w, "%-8s", " ")
+		}
+	}
+	if leaf, ok := n.(*bplusLeaf); ok {
+		fmt.Fprintf(w, "leaf:")
+		for i := 0; i < leaf.entries; i++ {
+			fmt.Fprintf(w, " %d", leaf.kvs[i].key)
+		}
+		fmt.Fprintln(w)
+		return
+	}
+
+	nonLeaf := n.(*bplusNonLeaf)
+	fmt.Fprintf(w, "node:")
+	for i := 0; i < nonLeaf.children-1; i++ {
+		fmt.Fprintf(w, " %d", nonLeaf.key[i])
+	}
+	fmt.Fprintln(w)
+	for i := 0; i < nonLeaf.children; i++ {
+		dumpText(w, nonLeaf.subPtr[i], append(more, i < nonLeaf.children-1))
+	}
+}
+
+func dumpDOT(w *bufio.Writer, tree *BPlusTree) {
+	fmt.Fprintln(w, "digraph bplustree {")
+	fmt.Fprintln(w, "\tnode [shape=record];")
+	if tree.root != nil {
+		var id int
+		var leaves []int
+		var walk func(n node) int
+		walk = func(n node) int {
+			self := id
+			id++
+			if leaf, ok := n.(*bplusLeaf); ok {
+				fmt.Fprintf(w, "\tn%d [label=\"", self)
+				for i := 0; i < leaf.entries; i++ {
+					if i > 0 {
+						fmt.Fprintf(w, "|")
+					}
+					fmt.Fprintf(w, "%d", leaf.kvs[i].key)
+				}
+				fmt.Fprintln(w, "\", style=filled, fillcolor=lightgrey];")
+				leaves = append(leaves, self)
+				return self
+			}
+			nonLeaf := n.(*bplusNonLeaf)
+			fmt.Fprintf(w, "\tn%d [label=\"<p0>", self)
+			for i := 0; i < nonLeaf.children-1; i++ {
+				fmt.Fprintf(w, "|%d|<p%d>", nonLeaf.key[i], i+1)
+			}
+			fmt.Fprintln(w, "\"];")
+			for i := 0; i < nonLeaf.children; i++ {
+				child := walk(nonLeaf.subPtr[i])
+				fmt.Fprintf(w, "\tn%d:p%d -> n%d;\n", self, i, child)
+			}
+			return self
+		}
+		walk(tree.root)
+		for i := 1; i < len(leaves); i++ {
+			fmt.Fprintf(w, "\tn%d -> n%d [style=dashed, constraint=false];\n", leaves[i-1], leaves[i])
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+type dumpJSONNode struct {
+	Keys     []KeyType       `json:"keys"`
+	Values   []DataType      `json:"values,omitempty"`
+	Children []*dumpJSONNode `json:"children,omitempty"`
+}
+
+func dumpJSONTree(tree *BPlusTree) interface{} {
+	var root *dumpJSONNode
+	if tree.root != nil {
+		root = dumpJSONSubtree(tree.root)
+	}
+	return struct {
+		Order   int           `json:"order"`
+		Entries int           `json:"entries"`
+		Level   int           `json:"level"`
+		Count   int           `json:"count"`
+		Root    *dumpJSONNode `json:"root"`
+	}{tree.order, tree.entries, tree.level, tree.count, root}
+}
+
+func dumpJSONSubtree(n node) *dumpJSONNode {
+	out := &dumpJSONNode{Keys: []KeyType{}}
+	if leaf, ok := n.(*bplusLeaf); ok {
+		for i := 0; i < leaf.entries; i++ {
+			out.Keys = append(out.Keys, leaf.kvs[i].key)
+			out.Values = append(out.Values, leaf.kvs[i].value)
+		}
+		return out
+	}
+	nonLeaf := n.(*bplusNonLeaf)
+	out.Keys = append(out.Keys, nonLeaf.key[:nonLeaf.children-1]...)
+	for i := 0; i < nonLeaf.children; i++ {
+		out.Children = append(out.Children, dumpJSONSubtree(nonLeaf.subPtr[i]))
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package bplustree
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDumpTo(t *testing.T) {
+	tree := New(3, 3)
+	for i := 1; i <= 30; i++ {
+		tree.Insert(i, i)
+	}
+	for i := 5; i <= 12; i++ {
+		tree.Delete(i)
+	}
+
+	var b strings.Builder
+	if err := tree.DumpTo(&b, DumpText); err != nil {
+		t.Fatal(err)
+	}
+	want := `node: 17
++-------node: 13
+|       +-------node: 3
+|       |       +-------leaf: 1 2
+|       |       +-------leaf: 3 4
+|       +-------node: 15
+|               +-------leaf: 13 14
+|               +-------leaf: 15 16
++-------node: 21 25
+        +-------node: 19
+        |       +-------leaf: 17 18
+        |       +-------leaf: 19 20
+        +-------node: 23
+        |       +-------leaf: 21 22
+        |       +-------leaf: 23 24
+        +-------node: 27 29
+                +-------leaf: 25 26
+                +-------leaf: 27 28
+                +-------leaf: 29 30
+`
+	if b.String() != want {
+		t.Fatalf("text dump:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := tree.DumpTo(&b, DumpDOT); err != nil {
+		t.Fatal(err)
+	}
+	dot := b.String()
+	if !strings.HasPrefix(dot, "digraph bplustree {") || strings.Count(dot, "style=dashed") != 10 ||
+		strings.Count(dot, " -> ") != 10+18 {
+		t.Fatalf("DOT dump:\n%s", dot)
+	}
+
+	b.Reset()
+	if err := tree.DumpTo(&b, DumpJSON); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Level int
+		Count int
+		Root  struct {
+			Keys     []KeyType
+			Children []json.RawMessage
+		}
+	}
+	if err := json.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Level != 3 || doc.Count != 22 || len(doc.Root.Keys) != 1 || len(doc.Root.Children) != 2 {
+		t.Fatalf("JSON dump: %s", b.String())
+	}
+
+	if err := New(3, 3).DumpTo(&b, DumpFormat(-1)); err == nil {
+		t.Fatalf("unknown format accepted")
+	}
+}
@@ -0,0 +1,121 @@
+package bplustree
+
+import (
+	"container/list"
+	"unsafe"
+)
+
+// EvictPolicy chooses the pair a bounded tree removes when it is over its
+// limit.
+type EvictPolicy int
+
+const (
+	/** the pair with the smallest key */
+	EvictSmallest EvictPolicy = iota
+	/** the pair with the greatest key */
+	EvictLargest
+	/** the pair least recently inserted, updated or found by Search */
+	EvictLRU
+)
+
+// evictLimit bounds the size of a tree.
+type evictLimit struct {
+	maxItems int
+	maxBytes int64
+	policy   EvictPolicy
+	onEvict  func(key KeyType, value DataType)
+	/** keys in use order, the most recent first, for EvictLRU */
+	lru   *list.List
+	elems map[KeyType]*list.Element
+}
+
+// WithLimit caps the tree at maxItems pairs and at about maxBytes bytes, a
+// zero limit being no cap. A pair is reckoned at its share of a full leaf,
+// so the estimate is a lower bound of the memory held. Once Insert,
+// InsertMany or Finger.Insert take the tree over a limit, pairs are removed
+// by policy until it is back under. EvictLRU keeps a use list with an
+// entry per key, and New panics when it is combined with Multi.
+func WithLimit(maxItems int, maxBytes int64, policy EvictPolicy) Option {
+	return func(tree *BPlusTree) {
+		if tree.limit == nil {
+			tree.limit = new(evictLimit)
+		}
+		tree.limit.maxItems, tree.limit.maxBytes, tree.limit.policy = maxItems, maxBytes, policy
+		if policy == EvictLRU {
+			tree.limit.lru = list.New()
+			tree.limit.elems = make(map[KeyType]*list.Element)
+		}
+	}
+}
+
+// WithEvictCallback makes a bounded tree call fn with every pair it
+// evicts, after removing it.
+func WithEvictCallback(fn func(key KeyType, value DataType)) Option {
+	return func(tree *BPlusTree) {
+		if tree.limit == nil {
+			tree.limit = new(evictLimit)
+		}
+		tree.limit.onEvict = fn
+	}
+}
+
+// approxBytes estimates the memory held by the pairs of the tree.
+func (tree *BPlusTree) approxBytes() int64 {
+	return int64(tree.count) * int64(unsafe.Sizeof(bplusLeaf{})) / int64(tree.entries)
+}
+
+func (tree *BPlusTree) overLimit() bool {
+	l := tree.limit
+	return l.maxItems > 0 && tree.count > l.maxItems || l.maxBytes > 0 && tree.approxBytes() > l.maxBytes
+}
+
+// used records an access to key for EvictLRU.
+func (tree *BPlusTree) used(key KeyType) {
+	l := tree.limit
+	if l == nil || l.lru == nil {
+		return
+	}
+	if e, ok := l.elems[key]; ok {
+		l.lru.MoveToFront(e)
+	} else {
+		l.elems[key] = l.lru.PushFront(key)
+	}
+}
+
+// unused drops the use list entry of a removed key.
+func (tree *BPlusTree) unused(key KeyType) {
+	l := tree.limit
+	if l == nil || l.lru == nil {
+		return
+	}
+	if e, ok := l.elems[key]; ok {
+		l.lru.Remove(e)
+		delete(l.elems, key)
+	}
+}
+
+// evict removes pairs by policy while the tree is over its limit. An open
+// transaction holds eviction back until it commits, so that Rollback finds
+// every pair it has to undo.
+func (tree *BPlusTree) evict() {
+	if tree.limit == nil || tree.txn != nil {
+		return
+	}
+	for tree.root != nil && tree.overLimit() {
+		leaf, i := tree.firstLeaf, 0
+		switch tree.limit.policy {
+		case EvictLargest:
+			leaf = tree.firstLeaf.prev
+			i = leaf.entries - 1
+		case EvictLRU:
+			key := tree.limit.lru.Back().Value.(KeyType)
+			leaf, i = tree.seekFirst(key)
+		}
+		key, value := leaf.kvs[i].key, leaf.kvs[i].value
+		tree.leafRemove(leaf, i)
+		tree.counters.evictions++
+		if tree.limit.onEvict != nil {
+			tree.limit.onEvict(key, value)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package bplustree
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestEvict(t *testing.T) {
+	for _, tc := range []struct {
+		policy EvictPolicy
+		want   []KeyType
+	}{
+		{EvictSmallest, []KeyType{7, 8, 9}},
+		{EvictLargest, []KeyType{0, 1, 2}},
+		/* 0 and 1 were used after the keys inserted later */
+		{EvictLRU, []KeyType{0, 1, 9}},
+	} {
+		var evicted []KeyType
+		tree := New(3, 3, WithLimit(3, 0, tc.policy), WithEvictCallback(func(key KeyType, value DataType) {
+			if key != value {
+				t.Fatalf("evicted %d with value %d", key, value)
+			}
+			evicted = append(evicted, key)
+		}))
+		for k := 0; k < 10; k++ {
+			if k >= 3 {
+				tree.Search(0)
+				tree.Update(1, 1)
+			}
+			tree.Insert(k, k)
+		}
+		if tree.Len() != 3 || len(evicted) != 7 {
+			t.Fatalf("policy %d: %d keys left, %d evicted", tc.policy, tree.Len(), len(evicted))
+		}
+		i := 0
+		for k := range tree.Ascend(0, 9) {
+			if k != tc.want[i] {
+				t.Fatalf("policy %d: key %d is %d, want %d", tc.policy, i, k, tc.want[i])
+			}
+			i++
+		}
+		if st := tree.Stats(); st.Evictions != 7 {
+			t.Fatalf("policy %d: %d evictions", tc.policy, st.Evictions)
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestEvictBytes(t *testing.T) {
+	perPair := int64(unsafe.Sizeof(bplusLeaf{})) / 4
+	tree := New(4, 4, WithLimit(0, 50*perPair, EvictSmallest))
+	for k := 0; k < 200; k++ {
+		tree.Insert(k, k)
+	}
+	if tree.Len() != 50 {
+		t.Fatalf("%d keys left, want 50", tree.Len())
+	}
+	if it := tree.First(); it.Key() != 150 {
+		t.Fatalf("smallest key left is %d, want 150", it.Key())
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEvictLRUMulti(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("EvictLRU accepted for a multimap")
+		}
+	}()
+	New(3, 3, WithLimit(3, 0, EvictLRU), Multi())
+}
@@ -0,0 +1,163 @@
+package bplustree
+
+// leafCursor remembers the leaf reached by the last descent together with
+// the key range its parents route to it, so that following keys close to
+// the last one can reuse it without starting again from the root. The leaf
+// is dropped once the tree version moves on.
+type leafCursor struct {
+	tree    *BPlusTree
+	leaf    *bplusLeaf
+	version uint64
+	/** bounds taken from the separators passed on the way down */
+	lo, hi       KeyType
+	hasLo, hasHi bool
+	/** a bound narrowed by step rather than taken from a separator */
+	loInexact, hiInexact bool
+	/** descent of seekFirst, equal keys are routed to the left child */
+	lower bool
+}
+
+// seek returns the leaf key is routed to, descending only when neither the
+// cached leaf nor one of its neighbours covers key.
+func (c *leafCursor) seek(key KeyType) *bplusLeaf {
+	if c.leaf != nil && c.version == c.tree.version {
+		if c.contains(key) || c.step(key) {
+			return c.leaf
+		}
+	}
+	c.leaf, c.hasLo, c.hasHi = nil, false, false
+	c.loInexact, c.hiInexact = false, false
+	c.version = c.tree.version
+	node := c.tree.root
+	for node != nil {
+		if ln, ok := node.(*bplusLeaf); ok {
+			c.leaf = ln
+			break
+		}
+		nln := node.(*bplusNonLeaf)
+		var i int
+		if c.lower {
+			i = nln.keyLowerSearch(key)
+		} else if j, found := nln.keySearch(key); found {
+			i = j + 1
+		} else {
+			i = j
+		}
+		/* the innermost separators are the tightest bounds */
+		if i > 0 {
+			c.lo, c.hasLo = nln.key[i-1], true
+		}
+		if i < nln.children-1 {
+			c.hi, c.hasHi = nln.key[i], true
+		}
+		node = nln.subPtr[i]
+	}
+	return c.leaf
+}
+
+func (c *leafCursor) contains(key KeyType) bool {
+	if c.lower {
+		return (!c.hasLo || key > c.lo) && (!c.hasHi || key <= c.hi)
+	}
+	return (!c.hasLo || key >= c.lo) && (!c.hasHi || key < c.hi)
+}
+
+// step moves the cursor to the previous or next leaf when key is routed
+// there. A neighbour's bounds are only known on the side it shares with
+// the cached leaf, so the other side is narrowed to its own keys, which
+// every separator routes to it.
+func (c *leafCursor) step(key KeyType) bool {
+	if c.lower {
+		return false
+	}
+	leaf := c.leaf
+	if c.hasHi && !c.hiInexact && key >= c.hi && !c.tree.listIsLastLeaf(leaf) {
+		next := leaf.next
+		if last := next.kvs[next.entries-1].key; key < last {
+			c.leaf = next
+			c.lo, c.hasLo, c.loInexact = c.hi, true, false
+			c.hi, c.hiInexact = last, true
+			return true
+		}
+	}
+	if c.hasLo && !c.loInexact && key < c.lo && leaf != c.tree.firstLeaf {
+		prev := leaf.prev
+		if first := prev.kvs[0].key; key >= first {
+			c.leaf = prev
+			c.hi, c.hasHi, c.hiInexact = c.lo, true, false
+			c.lo, c.loInexact = first, true
+			return true
+		}
+	}
+	return false
+}
+
+// Finger is a search handle for workloads with locality. It remembers the
+// leaf visited last and serves a key from that leaf or one of its
+// neighbours before falling back to a descent from the root. The hint is
+// dropped whenever the tree splits, merges or rebalances a node, so a
+// Finger stays correct across any mix of its own and other modifications.
+//
+// In multi mode Search and Delete look for the first of the equal keys,
+// which may lie left of the hinted leaf; they always descend from the root.
+type Finger struct {
+	c leafCursor
+}
+
+// Finger returns a new search handle on the tree.
+func (tree *BPlusTree) Finger() *Finger {
+	return &Finger{c: leafCursor{tree: tree}}
+}
+
+// Search behaves like BPlusTree.Search.
+func (f *Finger) Search(key KeyType) (DataType, bool) {
+	tree := f.c.tree
+	if tree.multi {
+		return tree.Search(key)
+	}
+	if tree.expire(key) {
+		return 0, false
+	}
+	leaf := f.c.seek(key)
+	if leaf == nil {
+		return 0, false
+	}
+	if i, ok := leaf.keySearch(key); ok {
+		tree.used(key)
+		return leaf.kvs[i].value, true
+	}
+	return 0, false
+}
+
+// Insert behaves like BPlusTree.Insert.
+func (f *Finger) Insert(key KeyType, data DataType) int {
+	tree := f.c.tree
+	tree.expire(key)
+	leaf := f.c.seek(key)
+	if leaf == nil {
+		return tree.Insert(key, data)
+	}
+	ret := tree.leafInsert(leaf, key, data)
+	if ret == 0 {
+		tree.evict()
+	}
+	return ret
+}
+
+// Delete behaves like BPlusTree.Delete.
+func (f *Finger) Delete(key KeyType) int {
+	tree := f.c.tree
+	if tree.multi {
+		return tree.Delete(key)
+	}
+	leaf := f.c.seek(key)
+	if leaf == nil {
+		return -1
+	}
+	remove, ok := leaf.keySearch(key)
+	if !ok {
+		return -1
+	}
+	tree.leafRemove(leaf, remove)
+	return 0
+}
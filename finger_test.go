@@ -0,0 +1,138 @@
+package bplustree
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestFinger(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, cfg := range [][2]int{{3, 3}, {4, 5}, {MaxOrder, MaxEntries}} {
+		tree := New(cfg[0], cfg[1])
+		f, other := tree.Finger(), tree.Finger()
+		model := make(map[KeyType]DataType)
+		var pos KeyType
+		for i := 0; i < 20000; i++ {
+			/* mostly local steps with an occasional jump */
+			if r.Intn(50) == 0 {
+				pos = r.Intn(2000)
+			} else {
+				pos += r.Intn(9) - 4
+			}
+			key := pos
+			/* modifications through a second handle and the tree itself must drop the hint */
+			h := f
+			switch r.Intn(10) {
+			case 0:
+				h = other
+			case 1:
+				h = nil
+			}
+
+			_, exists := model[key]
+			switch r.Intn(3) {
+			case 0:
+				var ret int
+				if h != nil {
+					ret = h.Insert(key, i)
+				} else {
+					ret = tree.Insert(key, i)
+				}
+				if (ret == 0) == exists {
+					t.Fatalf("%v: Insert(%d) = %d, exists %v", cfg, key, ret, exists)
+				}
+				if !exists {
+					model[key] = i
+				}
+			case 1:
+				var ret int
+				if h != nil {
+					ret = h.Delete(key)
+				} else {
+					ret = tree.Delete(key)
+				}
+				if (ret == 0) != exists {
+					t.Fatalf("%v: Delete(%d) = %d, exists %v", cfg, key, ret, exists)
+				}
+				delete(model, key)
+			default:
+				v, ok := f.Search(key)
+				if ok != exists || v != model[key] {
+					t.Fatalf("%v: Search(%d) = %d, %v, want %d, %v", cfg, key, v, ok, model[key], exists)
+				}
+			}
+			if i%500 == 0 {
+				if err := tree.Verify(); err != nil {
+					t.Fatalf("%v step %d: %v", cfg, i, err)
+				}
+			}
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		if tree.Len() != len(model) {
+			t.Fatalf("%v: Len() = %d, want %d", cfg, tree.Len(), len(model))
+		}
+	}
+}
+
+func TestFingerNeighbour(t *testing.T) {
+	tree := New(4, 4)
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+	f := tree.Finger()
+	f.Search(50)
+	leaf := f.c.leaf
+	/* the next leaf is reached through the ring without a new descent */
+	key := leaf.next.kvs[0].key
+	if v, ok := f.Search(key); !ok || v != key {
+		t.Fatalf("Search(%d) = %d, %v", key, v, ok)
+	}
+	if f.c.leaf != leaf.next || f.c.version != tree.version {
+		t.Fatal("finger did not step to the next leaf")
+	}
+	key = leaf.prev.kvs[0].key
+	if v, ok := f.Search(key); !ok || v != key {
+		t.Fatalf("Search(%d) = %d, %v", key, v, ok)
+	}
+}
+
+func TestFingerSeek(t *testing.T) {
+	tree := New(3, 3)
+	for i := 0; i < 30; i++ {
+		tree.Insert(i*2, i)
+	}
+	/* a cursor walking every key lands where a fresh descent does */
+	c := leafCursor{tree: tree}
+	for key := -5; key < 65; key++ {
+		fresh := leafCursor{tree: tree}
+		if leaf := c.seek(key); leaf != fresh.seek(key) {
+			t.Fatalf("seek(%d) reached the wrong leaf", key)
+		}
+	}
+}
+
+func TestFingerExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tree := New(3, 3, WithClock(func() time.Time { return now }), WithLimit(3, 0, EvictLRU))
+	f := tree.Finger()
+	tree.InsertWithTTL(0, 0, time.Minute)
+	tree.Insert(1, 1)
+	tree.Insert(2, 2)
+	now = now.Add(2 * time.Minute)
+	if _, ok := f.Search(0); ok || tree.Len() != 2 {
+		t.Fatalf("expired key found, %d keys left", tree.Len())
+	}
+	/* 1 becomes the most recently used, 2 the least */
+	f.Search(1)
+	tree.Insert(3, 3)
+	tree.Insert(4, 4)
+	if _, ok := f.Search(2); ok {
+		t.Fatal("2 not evicted")
+	}
+	if _, ok := f.Search(1); !ok {
+		t.Fatal("1 evicted after the finger used it")
+	}
+}
@@ -0,0 +1,135 @@
+package bplustree
+
+import (
+	"sort"
+	"testing"
+)
+
+const (
+	fuzzInsert = iota
+	fuzzDelete
+	fuzzSearch
+	fuzzGetRange
+	fuzzSeek
+	fuzzReverse
+	fuzzOps
+)
+
+// fuzzSeed encodes a tree configuration and operations the way FuzzTree
+// decodes them.
+func fuzzSeed(order, entries int, ops ...[2]int) []byte {
+	data := []byte{byte(order - 3), byte(entries - 2)}
+	for _, op := range ops {
+		data = append(data, byte(op[0]), byte(op[1]))
+	}
+	return data
+}
+
+func fuzzRange(op int, from, to int) [][2]int {
+	var ops [][2]int
+	for k := from; ; {
+		ops = append(ops, [2]int{op, k})
+		if k == to {
+			return ops
+		}
+		if from < to {
+			k++
+		} else {
+			k--
+		}
+	}
+}
+
+func FuzzTree(f *testing.F) {
+	f.Add(fuzzSeed(3, 3, [2]int{fuzzInsert, 3}, [2]int{fuzzInsert, 4}, [2]int{fuzzInsert, 6},
+		[2]int{fuzzInsert, 7}, [2]int{fuzzInsert, 5}, [2]int{fuzzInsert, 8}, [2]int{fuzzInsert, 2},
+		[2]int{fuzzSearch, 6}, [2]int{fuzzGetRange, 0}, [2]int{fuzzDelete, 5}))
+	for _, order := range []int{3, 4, 7} {
+		f.Add(fuzzSeed(order, 10, append(fuzzRange(fuzzInsert, 1, 100), fuzzRange(fuzzDelete, 1, 100)...)...))
+		f.Add(fuzzSeed(order, 3, append(fuzzRange(fuzzInsert, 1, 100), fuzzRange(fuzzDelete, 100, 1)...)...))
+		f.Add(fuzzSeed(order, 2, append(fuzzRange(fuzzInsert, 100, 1), fuzzRange(fuzzDelete, 1, 100)...)...))
+		f.Add(fuzzSeed(order, 5, append(fuzzRange(fuzzInsert, 100, 1), fuzzRange(fuzzSeek, 0, 20)...)...))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) < 2 {
+			return
+		}
+		order := 3 + int(data[0])%14
+		entries := 2 + int(data[1])%15
+		data = data[2:]
+
+		tree := New(order, entries)
+		model := make(map[KeyType]DataType)
+		sorted := func() []KeyType {
+			keys := make([]KeyType, 0, len(model))
+			for k := range model {
+				keys = append(keys, k)
+			}
+			sort.Ints(keys)
+			return keys
+		}
+		for step := 0; len(data) >= 2; step++ {
+			op, key := int(data[0])%fuzzOps, KeyType(data[1])
+			data = data[2:]
+			switch op {
+			case fuzzInsert:
+				_, exists := model[key]
+				if got := tree.Insert(key, step); (got == 0) == exists {
+					t.Fatalf("step %d: Insert(%d) = %d, present %v", step, key, got, exists)
+				}
+				if !exists {
+					model[key] = step
+				}
+			case fuzzDelete:
+				_, exists := model[key]
+				if got := tree.Delete(key); (got == 0) != exists {
+					t.Fatalf("step %d: Delete(%d) = %d, present %v", step, key, got, exists)
+				}
+				delete(model, key)
+			case fuzzSearch:
+				want, exists := model[key]
+				if got, ok := tree.Search(key); ok != exists || got != want {
+					t.Fatalf("step %d: Search(%d) = %d, %v, want %d, %v", step, key, got, ok, want, exists)
+				}
+			case fuzzGetRange:
+				hi := key + 16
+				var want DataType
+				var exists bool
+				for _, k := range sorted() {
+					if k >= key && k <= hi {
+						want, exists = model[k], true
+					}
+				}
+				if got, ok := tree.GetRange(hi, key); ok != exists || got != want {
+					t.Fatalf("step %d: GetRange(%d, %d) = %d, %v, want %d, %v", step, hi, key, got, ok, want, exists)
+				}
+			case fuzzSeek, fuzzReverse:
+				keys := sorted()
+				j := sort.SearchInts(keys, key)
+				it := tree.Seek(key)
+				for n := 0; n < 8; n++ {
+					if j < 0 || j >= len(keys) {
+						if it.Valid() {
+							t.Fatalf("step %d: iterator from %d still at %d", step, key, it.Key())
+						}
+						break
+					}
+					if !it.Valid() || it.Key() != keys[j] || it.Value() != model[keys[j]] {
+						t.Fatalf("step %d: iterator from %d lost key %d", step, key, keys[j])
+					}
+					if op == fuzzSeek {
+						it.Next()
+						j++
+					} else {
+						it.Prev()
+						j--
+					}
+				}
+			}
+			if err := tree.Verify(); err != nil {
+				t.Fatalf("step %d: %v", step, err)
+			}
+		}
+	})
+}
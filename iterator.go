@@ -0,0 +1,405 @@
+package bplustree
+
+import (
+	"bytes"
+	"errors"
+	"iter"
+)
+
+// Iterator is a cursor over the key-value pairs of a BPlusTree in key
+// order. It walks the leaf ring and by default is only valid while the
+// tree is not modified; Mode selects another behaviour. Expired keys are
+// skipped.
+type Iterator struct {
+	tree *BPlusTree
+	leaf *bplusLeaf
+	i    int
+
+	mode IterMode
+	/** tree.mods when the iterator last checked it */
+	mods uint64
+	err  error
+	/** the key last positioned at and its rank among equal keys */
+	key    KeyType
+	seen   int
+	hasKey bool
+	/** repositioned past its deleted key, the next key is already current */
+	moved bool
+}
+
+// IterMode selects how an Iterator reacts to inserts and removals made
+// in its tree after it was positioned.
+type IterMode int
+
+const (
+	/** the iterator must not be used after a modification */
+	IterUnchecked IterMode = iota
+	/** the iterator turns invalid and Err returns ErrModified */
+	IterFailFast
+	/** the iterator finds its place again by the last key it was at */
+	IterReposition
+)
+
+// ErrModified is returned by the Err method of a fail-fast iterator whose
+// tree was modified.
+var ErrModified = errors.New("bplustree: tree modified during iteration")
+
+// Seek returns an iterator positioned at the first key not less than key.
+func (tree *BPlusTree) Seek(key KeyType) *Iterator {
+	leaf, i := tree.seekFirst(key)
+	it := &Iterator{tree: tree, leaf: leaf, i: i, mods: tree.mods}
+	it.skipExpired(true)
+	return it
+}
+
+// First returns an iterator positioned at the smallest key.
+func (tree *BPlusTree) First() *Iterator {
+	it := &Iterator{tree: tree, mods: tree.mods}
+	if tree.root != nil {
+		it.leaf = tree.firstLeaf
+	}
+	it.skipExpired(true)
+	return it
+}
+
+// Last returns an iterator positioned at the greatest key.
+func (tree *BPlusTree) Last() *Iterator {
+	it := &Iterator{tree: tree, mods: tree.mods}
+	if tree.root != nil {
+		it.leaf = tree.firstLeaf.prev
+		it.i = it.leaf.entries - 1
+	}
+	it.skipExpired(false)
+	return it
+}
+
+// Mode sets how the iterator reacts to later modifications of its tree
+// and returns it. A repositioning iterator whose key was removed moves to
+// the next greater key, which Next then keeps rather than skips. Equal
+// keys of a multimap are told apart by their rank among the equal keys,
+// so removing an equal key before the iterator moves it one key on.
+func (it *Iterator) Mode(mode IterMode) *Iterator {
+	it.mode = mode
+	it.sync()
+	it.mark(false)
+	return it
+}
+
+// Err returns ErrModified once a fail-fast iterator has seen its tree
+// modified, and nil otherwise.
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Valid reports whether the iterator is positioned at a key.
+func (it *Iterator) Valid() bool {
+	it.sync()
+	return it.leaf != nil
+}
+
+// Key returns the key at the iterator position, or zero once the iterator
+// is not valid.
+func (it *Iterator) Key() KeyType {
+	it.sync()
+	if it.leaf == nil {
+		return 0
+	}
+	return it.leaf.kvs[it.i].key
+}
+
+// Value returns the value at the iterator position, or zero once the
+// iterator is not valid.
+func (it *Iterator) Value() DataType {
+	it.sync()
+	if it.leaf == nil {
+		return 0
+	}
+	return it.leaf.kvs[it.i].value
+}
+
+// Next moves to the next key.
+func (it *Iterator) Next() {
+	it.sync()
+	if it.moved {
+		it.moved = false
+		return
+	}
+	if it.leaf == nil {
+		return
+	}
+	it.leaf, it.i = it.tree.nextPos(it.leaf, it.i)
+	it.skipExpired(true)
+	it.mark(true)
+}
+
+// Prev moves to the previous key.
+func (it *Iterator) Prev() {
+	it.sync()
+	if it.moved && it.leaf == nil {
+		/* the removed key was the greatest */
+		last := it.tree.Last()
+		it.leaf, it.i = last.leaf, last.i
+	} else if it.leaf != nil {
+		it.leaf, it.i = it.tree.prevPos(it.leaf, it.i)
+		it.skipExpired(false)
+	}
+	it.moved = false
+	it.mark(false)
+}
+
+// sync brings the iterator up to date with the modifications of its tree
+// since it last checked, as its mode says.
+func (it *Iterator) sync() {
+	if it.mode == IterUnchecked || it.mods == it.tree.mods {
+		return
+	}
+	it.mods = it.tree.mods
+	if it.mode == IterFailFast {
+		it.leaf, it.err = nil, ErrModified
+		return
+	}
+	if !it.hasKey || it.leaf == nil && !it.moved {
+		return
+	}
+	leaf, i := it.tree.seekFirst(it.key)
+	for n := 0; n < it.seen && leaf != nil && leaf.kvs[i].key == it.key; n++ {
+		leaf, i = it.tree.nextPos(leaf, i)
+	}
+	it.leaf, it.i = leaf, i
+	it.skipExpired(true)
+	moved := it.moved || it.leaf == nil || it.Key() != it.key
+	it.mark(false)
+	it.moved = moved
+}
+
+// mark records the position of a repositioning iterator, after a step
+// forward if next is set.
+func (it *Iterator) mark(next bool) {
+	if it.mode != IterReposition || it.leaf == nil {
+		return
+	}
+	key := it.Key()
+	switch {
+	case !it.tree.multi:
+		it.seen = 0
+	case next && it.hasKey && key == it.key:
+		it.seen++
+	default:
+		it.seen = 0
+		for leaf, i := it.tree.prevPos(it.leaf, it.i); leaf != nil && leaf.kvs[i].key == key; leaf, i = it.tree.prevPos(leaf, i) {
+			it.seen++
+		}
+	}
+	it.key, it.hasKey = key, true
+}
+
+// skipExpired steps forward or backward past expired keys.
+func (it *Iterator) skipExpired(forward bool) {
+	for it.tree.ttl != nil && it.leaf != nil && it.tree.expired(it.Key()) {
+		if forward {
+			it.leaf, it.i = it.tree.nextPos(it.leaf, it.i)
+		} else {
+			it.leaf, it.i = it.tree.prevPos(it.leaf, it.i)
+		}
+	}
+}
+
+// prevPos steps to the entry before leaf.kvs[i], returning a nil leaf
+// before the first entry.
+func (tree *BPlusTree) prevPos(leaf *bplusLeaf, i int) (*bplusLeaf, int) {
+	if i > 0 {
+		return leaf, i - 1
+	}
+	if leaf == tree.firstLeaf {
+		return nil, 0
+	}
+	return leaf.prev, leaf.prev.entries - 1
+}
+
+// Ascend returns an iterator over the pairs with keys between lo and hi
+// inclusive, in ascending order. The loop body may insert and delete keys;
+// the walk repositions itself as an IterReposition iterator does.
+func (tree *BPlusTree) Ascend(lo, hi KeyType) iter.Seq2[KeyType, DataType] {
+	return func(yield func(KeyType, DataType) bool) {
+		for it := tree.Seek(lo).Mode(IterReposition); it.Valid() && it.Key() <= hi; it.Next() {
+			if !yield(it.Key(), it.Value()) {
+				return
+			}
+		}
+	}
+}
+
+// BytesIterator is a cursor over the key-value pairs of a BytesTree in key
+// order. When the tree is modified it finds its place again by the last key
+// it was at, as an IterReposition Iterator does: if that key was removed it
+// moves to the next greater key, which Next then keeps rather than skips.
+type BytesIterator struct {
+	tree *BytesTree
+	leaf *bytesLeaf
+	i    int
+
+	/** tree.mods when the iterator last checked it */
+	mods uint64
+	/** the key last positioned at, nil before the first */
+	key []byte
+	/** repositioned past its deleted key, the next key is already current */
+	moved bool
+}
+
+// Seek returns an iterator positioned at the first key not less than key.
+func (tree *BytesTree) Seek(key []byte) *BytesIterator {
+	leaf, i := tree.seekFirst(key)
+	it := &BytesIterator{tree: tree, leaf: leaf, i: i, mods: tree.mods}
+	it.mark()
+	return it
+}
+
+// First returns an iterator positioned at the smallest key.
+func (tree *BytesTree) First() *BytesIterator {
+	it := &BytesIterator{tree: tree, leaf: tree.firstLeaf, mods: tree.mods}
+	it.mark()
+	return it
+}
+
+// Last returns an iterator positioned at the greatest key.
+func (tree *BytesTree) Last() *BytesIterator {
+	it := &BytesIterator{tree: tree, mods: tree.mods}
+	if tree.root != nil {
+		it.leaf = tree.firstLeaf.prev
+		it.i = len(it.leaf.values) - 1
+	}
+	it.mark()
+	return it
+}
+
+// Valid reports whether the iterator is positioned at a key.
+func (it *BytesIterator) Valid() bool {
+	it.sync()
+	return it.leaf != nil
+}
+
+// Key returns a copy of the key at the iterator position, or nil once the
+// iterator is not valid.
+func (it *BytesIterator) Key() []byte {
+	it.sync()
+	if it.leaf == nil {
+		return nil
+	}
+	return it.leaf.key(it.i)
+}
+
+// Value returns the value at the iterator position, or zero once the
+// iterator is not valid.
+func (it *BytesIterator) Value() DataType {
+	it.sync()
+	if it.leaf == nil {
+		return 0
+	}
+	return it.leaf.values[it.i]
+}
+
+// Next moves to the next key.
+func (it *BytesIterator) Next() {
+	it.sync()
+	if it.moved {
+		it.moved = false
+		return
+	}
+	if it.leaf == nil {
+		return
+	}
+	it.leaf, it.i = it.tree.nextPos(it.leaf, it.i)
+	it.mark()
+}
+
+// Prev moves to the previous key.
+func (it *BytesIterator) Prev() {
+	it.sync()
+	if it.moved && it.leaf == nil {
+		/* the removed key was the greatest */
+		last := it.tree.Last()
+		it.leaf, it.i = last.leaf, last.i
+	} else if it.leaf != nil {
+		it.leaf, it.i = it.tree.prevPos(it.leaf, it.i)
+	}
+	it.moved = false
+	it.mark()
+}
+
+// sync finds the place of the iterator again after modifications of its
+// tree since it last checked.
+func (it *BytesIterator) sync() {
+	if it.mods == it.tree.mods {
+		return
+	}
+	it.mods = it.tree.mods
+	if it.key == nil || it.leaf == nil && !it.moved {
+		return
+	}
+	it.leaf, it.i = it.tree.seekFirst(it.key)
+	moved := it.moved || it.leaf == nil || !bytes.Equal(it.leaf.key(it.i), it.key)
+	it.mark()
+	it.moved = moved
+}
+
+// mark records the key the iterator is positioned at.
+func (it *BytesIterator) mark() {
+	if it.leaf != nil {
+		it.key = it.leaf.key(it.i)
+	}
+}
+
+// seekFirst returns the position of the first key not less than key, or a
+// nil leaf when every key is less.
+func (tree *BytesTree) seekFirst(key []byte) (*bytesLeaf, int) {
+	leaf := tree.findLeaf(key)
+	if leaf == nil {
+		return nil, 0
+	}
+	i, _ := leaf.keySearch(key)
+	if i < len(leaf.values) {
+		return leaf, i
+	}
+	/* every key of the leaf is less, the next leaf starts at the separator */
+	if leaf.next == tree.firstLeaf {
+		return nil, 0
+	}
+	return leaf.next, 0
+}
+
+// nextPos steps to the entry after leaf's entry i, returning a nil leaf
+// past the last entry.
+func (tree *BytesTree) nextPos(leaf *bytesLeaf, i int) (*bytesLeaf, int) {
+	if i++; i < len(leaf.values) {
+		return leaf, i
+	}
+	if leaf.next == tree.firstLeaf {
+		return nil, 0
+	}
+	return leaf.next, 0
+}
+
+// prevPos steps to the entry before leaf's entry i, returning a nil leaf
+// before the first entry.
+func (tree *BytesTree) prevPos(leaf *bytesLeaf, i int) (*bytesLeaf, int) {
+	if i > 0 {
+		return leaf, i - 1
+	}
+	if leaf == tree.firstLeaf {
+		return nil, 0
+	}
+	return leaf.prev, len(leaf.prev.values) - 1
+}
+
+// Ascend returns an iterator over the pairs with keys between lo and hi
+// inclusive, in ascending order. The loop body may insert and delete keys.
+func (tree *BytesTree) Ascend(lo, hi []byte) iter.Seq2[[]byte, DataType] {
+	return func(yield func([]byte, DataType) bool) {
+		for it := tree.Seek(lo); it.Valid(); it.Next() {
+			key := it.Key()
+			if bytes.Compare(key, hi) > 0 || !yield(key, it.Value()) {
+				return
+			}
+		}
+	}
+}
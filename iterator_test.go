@@ -0,0 +1,140 @@
+package bplustree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestIteratorFailFast(t *testing.T) {
+	tree := New(3, 3)
+	for k := 0; k < 100; k++ {
+		tree.Insert(k, k)
+	}
+	it := tree.First().Mode(IterFailFast)
+	var n int
+	for ; it.Valid(); it.Next() {
+		if n++; n == 10 {
+			tree.Delete(50)
+		}
+	}
+	if n != 10 || it.Err() != ErrModified {
+		t.Fatalf("stopped after %d keys with %v", n, it.Err())
+	}
+	/* updates change no positions */
+	it = tree.First().Mode(IterFailFast)
+	for ; it.Valid(); it.Next() {
+		tree.Update(it.Key(), -it.Key())
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+}
+
+func TestIteratorReposition(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 100; round++ {
+		tree := New(3, 3)
+		for k := 0; k < 300; k += 2 {
+			tree.Insert(k, k)
+		}
+		/* keys never removed must all be seen once, in order */
+		kept := make(map[KeyType]bool)
+		for k := 0; k < 300; k += 2 {
+			kept[k] = true
+		}
+		last := -1
+		var seen int
+		for it := tree.First().Mode(IterReposition); it.Valid(); it.Next() {
+			if it.Key() <= last {
+				t.Fatalf("round %d: key %d after %d", round, it.Key(), last)
+			}
+			last = it.Key()
+			if kept[last] {
+				seen++
+			}
+			for i := 0; i < r.Intn(6); i++ {
+				k := r.Intn(300)
+				if r.Intn(2) == 0 || k == last {
+					tree.Delete(k)
+					if kept[k] && k > last {
+						seen++
+					}
+					delete(kept, k)
+				} else {
+					tree.Insert(k|1, k)
+				}
+			}
+		}
+		if seen != 150 {
+			t.Fatalf("round %d: %d of the kept keys seen", round, seen)
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIteratorRepositionMulti(t *testing.T) {
+	tree := New(3, 3, Multi())
+	for _, k := range []KeyType{1, 2, 2, 2, 2, 3} {
+		tree.Insert(k, k)
+	}
+	it := tree.Seek(2).Mode(IterReposition)
+	it.Next()
+	it.Next()
+	/* at the third 2, a fifth one goes after it */
+	tree.Insert(2, 5)
+	for _, want := range []DataType{2, 5} {
+		if it.Next(); !it.Valid() || it.Key() != 2 || it.Value() != want {
+			t.Fatalf("missed the 2 with value %d", want)
+		}
+	}
+	if it.Next(); !it.Valid() || it.Key() != 3 {
+		t.Fatal("did not reach 3")
+	}
+	/* removing the current key leaves the iterator at the next one */
+	tree.Delete(3)
+	tree.Insert(4, 4)
+	if !it.Valid() || it.Key() != 4 {
+		t.Fatal("did not move to 4")
+	}
+	if it.Prev(); !it.Valid() || it.Key() != 2 {
+		t.Fatal("Prev from 4 missed 2")
+	}
+}
+
+func TestAscendModify(t *testing.T) {
+	tree := New(3, 3)
+	for k := 0; k < 100; k++ {
+		tree.Insert(k, k)
+	}
+	/* deleting the yielded key and the one after it skips neither */
+	var got []KeyType
+	for k := range tree.Ascend(10, 89) {
+		got = append(got, k)
+		tree.Delete(k)
+		if k%2 == 0 {
+			tree.Delete(k + 1)
+		}
+	}
+	if len(got) != 40 || got[0] != 10 || got[39] != 88 {
+		t.Fatalf("yielded %v", got)
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	it := tree.First().Mode(IterFailFast)
+	tree.Delete(0)
+	if it.Key() != 0 || it.Value() != 0 || it.Valid() || it.Err() != ErrModified {
+		t.Fatal("fail-fast iterator reads past a modification")
+	}
+	it = tree.Seek(5).Mode(IterReposition)
+	tree.Delete(5)
+	if it.Key() != 6 || it.Value() != 6 {
+		t.Fatalf("repositioned at %d: %d", it.Key(), it.Value())
+	}
+	if it.Next(); it.Key() != 6 {
+		t.Fatalf("Next after Key moved to %d", it.Key())
+	}
+}
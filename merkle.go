@@ -0,0 +1,246 @@
+package bplustree
+
+import (
+	"math"
+)
+
+// WithMerkle caches a hash in every node and keeps it up to date as the
+// tree changes, so that RootHash, RangeHash and Diff only rehash the nodes
+// modified since their last call. Without it they hash the whole tree.
+func WithMerkle() Option {
+	return func(tree *BPlusTree) {
+		tree.merkle = true
+	}
+}
+
+// touch marks the cached hashes and aggregates of n and its ancestors
+// stale.
+func (tree *BPlusTree) touch(n node) {
+	if !tree.merkle && tree.monoid == nil {
+		return
+	}
+	for bn := getNode(n); ; {
+		bn.stale()
+		if bn.parent == nil {
+			return
+		}
+		bn = &bn.parent.bplusNode
+	}
+}
+
+func mix64(x uint64) uint64 {
+	/* splitmix64 finalizer */
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+func entryHash(key KeyType, value DataType) uint64 {
+	return mix64(mix64(uint64(key)) ^ uint64(value))
+}
+
+// nodeHash returns the hash of the subtree rooted at n: the sum of the
+// hashes of its entries. A sum does not depend on how the entries are
+// spread over nodes, so trees of any shape holding the same pairs hash
+// the same.
+func (tree *BPlusTree) nodeHash(n node) uint64 {
+	bn := getNode(n)
+	if tree.merkle && bn.hashed {
+		return bn.hash
+	}
+	var h uint64
+	if leaf, ok := n.(*bplusLeaf); ok {
+		for i := 0; i < leaf.entries; i++ {
+			h += entryHash(leaf.kvs[i].key, leaf.kvs[i].value)
+		}
+	} else {
+		nl := n.(*bplusNonLeaf)
+		for i := 0; i < nl.children; i++ {
+			h += tree.nodeHash(nl.subPtr[i])
+		}
+	}
+	if tree.merkle {
+		bn.hash, bn.hashed = h, true
+	}
+	return h
+}
+
+// RootHash returns the hash of the tree's contents, 0 for an empty tree.
+func (tree *BPlusTree) RootHash() uint64 {
+	if tree.root == nil {
+		return 0
+	}
+	return tree.nodeHash(tree.root)
+}
+
+// keyBounds is the half-open key range [lo, hi), unbounded on a side
+// whose flag is unset.
+type keyBounds struct {
+	lo, hi       KeyType
+	hasLo, hasHi bool
+}
+
+func (b keyBounds) contains(key KeyType) bool {
+	return (!b.hasLo || key >= b.lo) && (!b.hasHi || key < b.hi)
+}
+
+/** whether every key routed between the separators of c lies in b */
+func (b keyBounds) covers(c keyBounds) bool {
+	return (!b.hasLo || c.hasLo && c.lo >= b.lo) && (!b.hasHi || c.hasHi && c.hi <= b.hi)
+}
+
+/** whether no key routed between the separators of c lies in b */
+func (b keyBounds) disjoint(c keyBounds) bool {
+	return b.hasHi && c.hasLo && c.lo >= b.hi || b.hasLo && c.hasHi && c.hi <= b.lo
+}
+
+/** bounds of child i of nl, nl's own bounds being b */
+func (b keyBounds) child(nl *bplusNonLeaf, i int) keyBounds {
+	c := b
+	if i > 0 {
+		c.lo, c.hasLo = nl.key[i-1], true
+	}
+	if i < nl.children-1 {
+		c.hi, c.hasHi = nl.key[i], true
+	}
+	return c
+}
+
+// boundsHash returns the hash of the pairs whose keys lie in b, using the
+// cached hash of every subtree b covers entirely.
+func (tree *BPlusTree) boundsHash(b keyBounds) uint64 {
+	if tree.root == nil {
+		return 0
+	}
+	var h uint64
+	var walk func(nd node, nb keyBounds)
+	walk = func(nd node, nb keyBounds) {
+		if leaf, ok := nd.(*bplusLeaf); ok {
+			for i := 0; i < leaf.entries; i++ {
+				if b.contains(leaf.kvs[i].key) {
+					h += entryHash(leaf.kvs[i].key, leaf.kvs[i].value)
+				}
+			}
+			return
+		}
+		nl := nd.(*bplusNonLeaf)
+		for i := 0; i < nl.children; i++ {
+			cb := nb.child(nl, i)
+			if b.disjoint(cb) {
+				continue
+			}
+			if b.covers(cb) && tree.merkle {
+				h += tree.nodeHash(nl.subPtr[i])
+				continue
+			}
+			walk(nl.subPtr[i], cb)
+		}
+	}
+	walk(tree.root, keyBounds{})
+	return h
+}
+
+// RangeHash returns the hash of the pairs whose keys lie between lo and hi
+// inclusive. Two trees hold the same pairs in a range iff, barring hash
+// collisions, their range hashes are equal.
+func (tree *BPlusTree) RangeHash(lo, hi KeyType) uint64 {
+	b := keyBounds{lo: lo, hasLo: true, hi: hi + 1, hasHi: true}
+	if hi == math.MaxInt {
+		b.hasHi = false
+	}
+	return tree.boundsHash(b)
+}
+
+// KeyDiff is a key whose pair differs between two trees.
+type KeyDiff struct {
+	Key KeyType
+	/** the key is missing from the first or the second tree */
+	Added, Removed bool
+	/** the values, zero for a missing side */
+	Old, New DataType
+}
+
+// Diff returns the keys whose pairs differ between tree and other, in key
+// order: keys only in other are Added, keys only in tree are Removed and
+// keys in both with different values carry both values. It descends the
+// nodes of tree and only enters a subtree when its hash differs from the
+// hash of other over the same key range. Both trees must not be multimaps.
+func (tree *BPlusTree) Diff(other *BPlusTree) []KeyDiff {
+	assert(!tree.multi && !other.multi)
+	var diffs []KeyDiff
+	if tree.root == nil {
+		for it := other.First(); it.Valid(); it.Next() {
+			diffs = append(diffs, KeyDiff{Key: it.Key(), Added: true, New: it.Value()})
+		}
+		return diffs
+	}
+	var walk func(nd node, b keyBounds)
+	walk = func(nd node, b keyBounds) {
+		if other.boundsHash(b) == tree.nodeHash(nd) {
+			return
+		}
+		if nl, ok := nd.(*bplusNonLeaf); ok {
+			for i := 0; i < nl.children; i++ {
+				walk(nl.subPtr[i], b.child(nl, i))
+			}
+			return
+		}
+		diffs = diffLeaf(diffs, nd.(*bplusLeaf), other, b)
+	}
+	walk(tree.root, keyBounds{})
+	return diffs
+}
+
+// diffLeaf merges the pairs of leaf with the pairs of other in the leaf's
+// bounds b.
+func diffLeaf(diffs []KeyDiff, leaf *bplusLeaf, other *BPlusTree, b keyBounds) []KeyDiff {
+	var it *Iterator
+	if b.hasLo {
+		it = other.Seek(b.lo)
+	} else {
+		it = other.First()
+	}
+	i := 0
+	for {
+		more := it.Valid() && b.contains(it.Key())
+		switch {
+		case i == leaf.entries && !more:
+			return diffs
+		case !more || i < leaf.entries && leaf.kvs[i].key < it.Key():
+			diffs = append(diffs, KeyDiff{Key: leaf.kvs[i].key, Removed: true, Old: leaf.kvs[i].value})
+			i++
+		case i == leaf.entries || it.Key() < leaf.kvs[i].key:
+			diffs = append(diffs, KeyDiff{Key: it.Key(), Added: true, New: it.Value()})
+			it.Next()
+		default:
+			if leaf.kvs[i].value != it.Value() {
+				diffs = append(diffs, KeyDiff{Key: it.Key(), Old: leaf.kvs[i].value, New: it.Value()})
+			}
+			i++
+			it.Next()
+		}
+	}
+}
+
+// SyncFrom makes tree hold the same pairs as src by applying the result of
+// tree.Diff(src), and returns the number of keys changed. Neither tree may
+// be a multimap.
+func (tree *BPlusTree) SyncFrom(src *BPlusTree) int {
+	diffs := tree.Diff(src)
+	for _, d := range diffs {
+		var ret int
+		switch {
+		case d.Added:
+			ret = tree.Insert(d.Key, d.New)
+		case d.Removed:
+			ret = tree.Delete(d.Key)
+		default:
+			ret = tree.Update(d.Key, d.New)
+		}
+		assert(ret == 0)
+	}
+	return len(diffs)
+}
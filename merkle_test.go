@@ -0,0 +1,116 @@
+package bplustree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestMerkle(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a, b := New(3, 3, WithMerkle()), New(5, 7)
+	model := make(map[KeyType]DataType)
+	for i := 0; i < 20000; i++ {
+		key := r.Intn(500)
+		switch r.Intn(4) {
+		case 0:
+			a.Delete(key)
+			b.Delete(key)
+			delete(model, key)
+		case 1:
+			a.Update(key, -i)
+			b.Update(key, -i)
+			if _, ok := model[key]; ok {
+				model[key] = -i
+			}
+		default:
+			a.Insert(key, i)
+			b.Insert(key, i)
+			if _, ok := model[key]; !ok {
+				model[key] = i
+			}
+		}
+		if i%97 == 0 {
+			/* a cached root hash of a different shape matches a full rehash */
+			if a.RootHash() != b.RootHash() {
+				t.Fatalf("step %d: root hashes differ", i)
+			}
+			lo := r.Intn(500)
+			hi := lo + r.Intn(100)
+			var want uint64
+			for k, v := range model {
+				if k >= lo && k <= hi {
+					want += entryHash(k, v)
+				}
+			}
+			if got := a.RangeHash(lo, hi); got != want {
+				t.Fatalf("step %d: RangeHash(%d, %d) = %#x, want %#x", i, lo, hi, got, want)
+			}
+			if err := a.Verify(); err != nil {
+				t.Fatalf("step %d: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		a, b := New(3, 3, WithMerkle()), New(4, 6, WithMerkle())
+		for i := 0; i < 1000; i++ {
+			key := r.Intn(2000)
+			a.Insert(key, key)
+			b.Insert(key, key)
+		}
+		/* diverge in a few places */
+		for i := 0; i < r.Intn(20); i++ {
+			key := r.Intn(2000)
+			switch r.Intn(3) {
+			case 0:
+				b.Delete(key)
+			case 1:
+				b.Insert(key, -key)
+			default:
+				b.Update(key, -key-1)
+			}
+		}
+
+		want := make(map[KeyType]KeyDiff)
+		for it := a.First(); it.Valid(); it.Next() {
+			if v, ok := b.Search(it.Key()); !ok {
+				want[it.Key()] = KeyDiff{Key: it.Key(), Removed: true, Old: it.Value()}
+			} else if v != it.Value() {
+				want[it.Key()] = KeyDiff{Key: it.Key(), Old: it.Value(), New: v}
+			}
+		}
+		for it := b.First(); it.Valid(); it.Next() {
+			if _, ok := a.Search(it.Key()); !ok {
+				want[it.Key()] = KeyDiff{Key: it.Key(), Added: true, New: it.Value()}
+			}
+		}
+		diffs := a.Diff(b)
+		if len(diffs) != len(want) {
+			t.Fatalf("round %d: %d diffs, want %d", round, len(diffs), len(want))
+		}
+		for i, d := range diffs {
+			if i > 0 && diffs[i-1].Key >= d.Key {
+				t.Fatalf("round %d: diffs out of order", round)
+			}
+			if want[d.Key] != d {
+				t.Fatalf("round %d: diff %+v, want %+v", round, d, want[d.Key])
+			}
+		}
+
+		if n := a.SyncFrom(b); n != len(want) {
+			t.Fatalf("round %d: SyncFrom changed %d keys, want %d", round, n, len(want))
+		}
+		if treeContents(a, false) != treeContents(b, false) || a.RootHash() != b.RootHash() {
+			t.Fatalf("round %d: trees differ after SyncFrom", round)
+		}
+		if err := a.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		if d := a.Diff(b); len(d) != 0 {
+			t.Fatalf("round %d: synced trees still differ: %v", round, d)
+		}
+	}
+}
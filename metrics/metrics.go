@@ -0,0 +1,256 @@
+// Package metrics exports operation and structure metrics of B+ trees in
+// the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/liwnn/bplustree"
+)
+
+// HeightBuckets are the upper bounds of the operation height histogram.
+var HeightBuckets = []int{1, 2, 3, 4, 5, 6, 8}
+
+const (
+	opInsert = iota
+	opSearch
+	opDelete
+	opKinds
+)
+
+var opNames = [opKinds]string{"insert", "search", "delete"}
+
+/** result label of a successful and of a failed operation, by kind */
+var resultNames = [opKinds][2]string{
+	{"ok", "duplicate"},
+	{"hit", "miss"},
+	{"hit", "miss"},
+}
+
+const (
+	nodeLeaf = iota
+	nodeInner
+	nodeKinds
+)
+
+var nodeNames = [nodeKinds]string{"leaf", "inner"}
+
+// Tree wraps a BPlusTree and counts its operations and structural changes.
+// Its methods are safe for concurrent use with scrapes, but the tree must
+// only be modified through the wrapper.
+type Tree struct {
+	name string
+	tree *bplustree.BPlusTree
+
+	mu          sync.Mutex
+	ops         [opKinds][2]uint64
+	height      []uint64 // one counter per HeightBuckets entry plus +Inf
+	heightSum   uint64
+	heightCount uint64
+	splits      [nodeKinds]uint64
+	merges      [nodeKinds]uint64
+	borrows     [nodeKinds]uint64
+}
+
+// Wrap returns a Tree reporting metrics for tree under the given name. It
+// installs itself as the tree's observer, replacing any previous one.
+func Wrap(name string, tree *bplustree.BPlusTree) *Tree {
+	t := &Tree{name: name, tree: tree, height: make([]uint64, len(HeightBuckets)+1)}
+	tree.SetObserver((*observer)(t))
+	return t
+}
+
+// Tree returns the wrapped tree. Modifying it directly bypasses the
+// operation counters.
+func (t *Tree) Tree() *bplustree.BPlusTree {
+	return t.tree
+}
+
+// record counts an operation started on a tree of height h. A descent from
+// the root visits one node per level, so h is the number of nodes the
+// lookup of the operation visits; the metric does not count extra
+// descents such as those that remove an expired key.
+func (t *Tree) record(op int, ok bool, h int) {
+	if ok {
+		t.ops[op][0]++
+	} else {
+		t.ops[op][1]++
+	}
+	i := 0
+	for i < len(HeightBuckets) && h > HeightBuckets[i] {
+		i++
+	}
+	t.height[i]++
+	t.heightSum += uint64(h)
+	t.heightCount++
+}
+
+// Insert inserts key into the wrapped tree like BPlusTree.Insert and
+// counts the result as ok or duplicate.
+func (t *Tree) Insert(key bplustree.KeyType, data bplustree.DataType) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.tree.Height()
+	ret := t.tree.Insert(key, data)
+	t.record(opInsert, ret == 0, h)
+	return ret
+}
+
+// Search looks key up in the wrapped tree like BPlusTree.Search and counts
+// the result as hit or miss.
+func (t *Tree) Search(key bplustree.KeyType) (bplustree.DataType, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.tree.Height()
+	data, ok := t.tree.Search(key)
+	t.record(opSearch, ok, h)
+	return data, ok
+}
+
+// Delete removes key from the wrapped tree like BPlusTree.Delete and
+// counts the result as hit or miss.
+func (t *Tree) Delete(key bplustree.KeyType) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h := t.tree.Height()
+	ret := t.tree.Delete(key)
+	t.record(opDelete, ret == 0, h)
+	return ret
+}
+
+// observer receives the structural changes of the wrapped tree. The
+// callbacks run inside Insert and Delete, which already hold the lock.
+type observer Tree
+
+func nodeKind(level int) int {
+	if level == 0 {
+		return nodeLeaf
+	}
+	return nodeInner
+}
+
+func (o *observer) OnLeafSplit(ev bplustree.SplitEvent)  { o.splits[nodeLeaf]++ }
+func (o *observer) OnInnerSplit(ev bplustree.SplitEvent) { o.splits[nodeInner]++ }
+func (o *observer) OnBorrow(ev bplustree.BorrowEvent)    { o.borrows[nodeKind(ev.Level)]++ }
+func (o *observer) OnMerge(ev bplustree.MergeEvent)      { o.merges[nodeKind(ev.Level)]++ }
+func (o *observer) OnRootGrow(level int)                 {}
+func (o *observer) OnRootShrink(level int)               {}
+
+// ServeHTTP writes the metrics of t in the Prometheus text format.
+func (t *Tree) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	Handler(t).ServeHTTP(w, r)
+}
+
+// Handler returns an http.Handler exposing the metrics of all the given
+// trees in the Prometheus text format.
+func Handler(trees ...*Tree) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = Write(w, trees...)
+	})
+}
+
+type snapshot struct {
+	name          string
+	ops           [opKinds][2]uint64
+	opHeight      []uint64
+	opHeightSum   uint64
+	opHeightCount uint64
+	splits        [nodeKinds]uint64
+	merges        [nodeKinds]uint64
+	borrows       [nodeKinds]uint64
+	entries       int
+	height        int
+}
+
+func (t *Tree) snapshot() snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return snapshot{
+		name:          escapeLabel(t.name),
+		ops:           t.ops,
+		opHeight:      append([]uint64(nil), t.height...),
+		opHeightSum:   t.heightSum,
+		opHeightCount: t.heightCount,
+		splits:        t.splits,
+		merges:        t.merges,
+		borrows:       t.borrows,
+		entries:       t.tree.Len(),
+		height:        t.tree.Height(),
+	}
+}
+
+// Write writes the metrics of all the given trees to w in the Prometheus
+// text format.
+func Write(w io.Writer, trees ...*Tree) error {
+	snaps := make([]snapshot, len(trees))
+	for i, t := range trees {
+		snaps[i] = t.snapshot()
+	}
+
+	bw := bufio.NewWriter(w)
+	header := func(name, typ, help string) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+	}
+
+	header("bplustree_operations_total", "counter", "Tree operations by type and result.")
+	for _, s := range snaps {
+		for op := 0; op < opKinds; op++ {
+			for r := 0; r < 2; r++ {
+				fmt.Fprintf(bw, "bplustree_operations_total{tree=\"%s\",op=\"%s\",result=\"%s\"} %d\n",
+					s.name, opNames[op], resultNames[op][r], s.ops[op][r])
+			}
+		}
+	}
+
+	header("bplustree_operation_height", "histogram", "Tree height, the nodes on a root-to-leaf path, when each operation started.")
+	for _, s := range snaps {
+		var cum uint64
+		for i, le := range HeightBuckets {
+			cum += s.opHeight[i]
+			fmt.Fprintf(bw, "bplustree_operation_height_bucket{tree=\"%s\",le=\"%d\"} %d\n", s.name, le, cum)
+		}
+		cum += s.opHeight[len(HeightBuckets)]
+		fmt.Fprintf(bw, "bplustree_operation_height_bucket{tree=\"%s\",le=\"+Inf\"} %d\n", s.name, cum)
+		fmt.Fprintf(bw, "bplustree_operation_height_sum{tree=\"%s\"} %d\n", s.name, s.opHeightSum)
+		fmt.Fprintf(bw, "bplustree_operation_height_count{tree=\"%s\"} %d\n", s.name, s.opHeightCount)
+	}
+
+	for _, c := range []struct {
+		name, help string
+		value      func(s *snapshot) [nodeKinds]uint64
+	}{
+		{"bplustree_splits_total", "Node splits by node kind.", func(s *snapshot) [nodeKinds]uint64 { return s.splits }},
+		{"bplustree_merges_total", "Node merges by node kind.", func(s *snapshot) [nodeKinds]uint64 { return s.merges }},
+		{"bplustree_borrows_total", "Entries borrowed from a sibling by node kind.", func(s *snapshot) [nodeKinds]uint64 { return s.borrows }},
+	} {
+		header(c.name, "counter", c.help)
+		for i := range snaps {
+			v := c.value(&snaps[i])
+			for k := 0; k < nodeKinds; k++ {
+				fmt.Fprintf(bw, "%s{tree=\"%s\",node=\"%s\"} %d\n", c.name, snaps[i].name, nodeNames[k], v[k])
+			}
+		}
+	}
+
+	header("bplustree_entries", "gauge", "Key-value pairs stored in the tree.")
+	for _, s := range snaps {
+		fmt.Fprintf(bw, "bplustree_entries{tree=\"%s\"} %d\n", s.name, s.entries)
+	}
+	header("bplustree_height", "gauge", "Node levels of the tree.")
+	for _, s := range snaps {
+		fmt.Fprintf(bw, "bplustree_height{tree=\"%s\"} %d\n", s.name, s.height)
+	}
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
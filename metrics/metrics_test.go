@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/liwnn/bplustree"
+)
+
+func TestHandler(t *testing.T) {
+	users := Wrap("users", bplustree.New(3, 3))
+	orders := Wrap(`or"ders`, bplustree.New(4, 4))
+	for i := 0; i < 50; i++ {
+		users.Insert(i, i)
+	}
+	users.Insert(7, 7)
+	users.Search(7)
+	users.Search(100)
+	for i := 0; i < 40; i++ {
+		users.Delete(i)
+	}
+	orders.Insert(1, 1)
+
+	rec := httptest.NewRecorder()
+	Handler(users, orders).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("Content-Type %q", ct)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	out := string(body)
+	for _, want := range []string{
+		`bplustree_operations_total{tree="users",op="insert",result="ok"} 50`,
+		`bplustree_operations_total{tree="users",op="insert",result="duplicate"} 1`,
+		`bplustree_operations_total{tree="users",op="search",result="hit"} 1`,
+		`bplustree_operations_total{tree="users",op="search",result="miss"} 1`,
+		`bplustree_operations_total{tree="users",op="delete",result="hit"} 40`,
+		`bplustree_operation_height_bucket{tree="users",le="+Inf"} 93`,
+		`bplustree_operation_height_count{tree="users"} 93`,
+		`bplustree_entries{tree="users"} 10`,
+		`bplustree_entries{tree="or\"ders"} 1`,
+		`bplustree_height{tree="or\"ders"} 1`,
+		/* the first insert grew the root but descended into an empty tree */
+		`bplustree_operation_height_sum{tree="or\"ders"} 0`,
+		"# TYPE bplustree_operation_height histogram",
+	} {
+		if !strings.Contains(out, want+"\n") {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `bplustree_splits_total{tree="users",node="leaf"} 0`) ||
+		strings.Contains(out, `bplustree_merges_total{tree="users",node="leaf"} 0`) {
+		t.Fatalf("structural counters not recorded:\n%s", out)
+	}
+	if strings.Count(out, "# TYPE bplustree_operations_total") != 1 {
+		t.Fatalf("metric family repeated:\n%s", out)
+	}
+}
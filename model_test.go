@@ -0,0 +1,180 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type modelOpKind int
+
+const (
+	opInsert modelOpKind = iota
+	opDelete
+	opSearch
+	opGetRange
+)
+
+type modelOp struct {
+	kind   modelOpKind
+	k1, k2 KeyType
+}
+
+func (op modelOp) String() string {
+	switch op.kind {
+	case opInsert:
+		return fmt.Sprintf("Insert(%d)", op.k1)
+	case opDelete:
+		return fmt.Sprintf("Delete(%d)", op.k1)
+	case opSearch:
+		return fmt.Sprintf("Search(%d)", op.k1)
+	default:
+		return fmt.Sprintf("GetRange(%d, %d)", op.k1, op.k2)
+	}
+}
+
+// modelRun applies ops to a fresh tree and a reference map, returning the
+// index of the first op whose result or resulting structure is wrong.
+func modelRun(order, entries int, ops []modelOp) (step int, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("panic: %v", e)
+		}
+	}()
+	tree := New(order, entries)
+	model := make(map[KeyType]DataType)
+	for step = range ops {
+		op := ops[step]
+		switch op.kind {
+		case opInsert:
+			_, exists := model[op.k1]
+			if got := tree.Insert(op.k1, -op.k1); (got == 0) == exists {
+				return step, fmt.Errorf("returned %d, key present %v", got, exists)
+			}
+			model[op.k1] = -op.k1
+		case opDelete:
+			_, exists := model[op.k1]
+			if got := tree.Delete(op.k1); (got == 0) != exists {
+				return step, fmt.Errorf("returned %d, key present %v", got, exists)
+			}
+			delete(model, op.k1)
+		case opSearch:
+			want, exists := model[op.k1]
+			if got, ok := tree.Search(op.k1); ok != exists || got != want {
+				return step, fmt.Errorf("returned %d, %v, want %d, %v", got, ok, want, exists)
+			}
+		case opGetRange:
+			var want DataType
+			var exists bool
+			lo, hi := op.k1, op.k2
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			for k, v := range model {
+				if k >= lo && k <= hi && (!exists || k > -want) {
+					want, exists = v, true
+				}
+			}
+			if got, ok := tree.GetRange(op.k1, op.k2); ok != exists || got != want {
+				return step, fmt.Errorf("returned %d, %v, want %d, %v", got, ok, want, exists)
+			}
+		}
+		if tree.Len() != len(model) {
+			return step, fmt.Errorf("Len() = %d, want %d", tree.Len(), len(model))
+		}
+		if err := tree.Verify(); err != nil {
+			return step, err
+		}
+	}
+
+	/* the leaf chain must hold exactly the model, in order */
+	keys := make([]KeyType, 0, len(model))
+	for k := range model {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	leaf, i := tree.seekFirst(minKey(keys))
+	for _, k := range keys {
+		if leaf == nil || leaf.kvs[i].key != k {
+			return len(ops) - 1, fmt.Errorf("leaf chain is missing key %d", k)
+		}
+		leaf, i = tree.nextPos(leaf, i)
+	}
+	if leaf != nil {
+		return len(ops) - 1, fmt.Errorf("leaf chain has extra key %d", leaf.kvs[i].key)
+	}
+	return 0, nil
+}
+
+func minKey(keys []KeyType) KeyType {
+	if len(keys) == 0 {
+		return 0
+	}
+	return keys[0]
+}
+
+// modelShrink removes ops while the run keeps failing.
+func modelShrink(order, entries int, ops []modelOp) []modelOp {
+	for changed := true; changed; {
+		changed = false
+		for i := len(ops) - 1; i >= 0; i-- {
+			try := append(append([]modelOp(nil), ops[:i]...), ops[i+1:]...)
+			if _, err := modelRun(order, entries, try); err != nil {
+				ops = try
+				changed = true
+			}
+		}
+	}
+	return ops
+}
+
+func modelOps(r *rand.Rand, n int, keySpace int) []modelOp {
+	ops := make([]modelOp, n)
+	for i := range ops {
+		op := modelOp{k1: r.Intn(keySpace), k2: r.Intn(keySpace)}
+		switch x := r.Intn(10); {
+		case x < 4:
+			op.kind = opInsert
+		case x < 7:
+			op.kind = opDelete
+		case x < 9:
+			op.kind = opSearch
+		default:
+			op.kind = opGetRange
+		}
+		ops[i] = op
+	}
+	return ops
+}
+
+func TestModel(t *testing.T) {
+	configs := [][2]int{
+		{3, 2}, {3, 3}, {3, 4}, {3, 7}, {4, 2}, {4, 4}, {5, 3}, {5, 5},
+		{6, 9}, {7, 10}, {8, 16}, {16, 4}, {MaxOrder, MaxEntries},
+	}
+	rounds, steps := 20, 1000
+	if testing.Short() {
+		rounds = 3
+	}
+	for _, cfg := range configs {
+		for round := 0; round < rounds; round++ {
+			seed := int64(round)
+			r := rand.New(rand.NewSource(seed))
+			ops := modelOps(r, steps, 20+round*25)
+			step, err := modelRun(cfg[0], cfg[1], ops)
+			if err == nil {
+				continue
+			}
+			ops = modelShrink(cfg[0], cfg[1], ops[:step+1])
+			step, err = modelRun(cfg[0], cfg[1], ops)
+			log := make([]string, len(ops))
+			for i, op := range ops {
+				log[i] = op.String()
+			}
+			t.Fatalf("New(%d, %d), seed %d: %s: %v\nreproduce with:\n\t%s",
+				cfg[0], cfg[1], seed, ops[step], err, strings.Join(log, "\n\t"))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package bplustree
+
+// SearchAll returns the values stored under key in insertion order.
+func (tree *BPlusTree) SearchAll(key KeyType) []DataType {
+	if tree.expire(key) {
+		return nil
+	}
+	var ret []DataType
+	leaf, i := tree.seekFirst(key)
+	for leaf != nil && leaf.kvs[i].key == key {
+		ret = append(ret, leaf.kvs[i].value)
+		leaf, i = tree.nextPos(leaf, i)
+	}
+	return ret
+}
+
+// Count returns the number of values stored under key.
+func (tree *BPlusTree) Count(key KeyType) int {
+	if tree.expire(key) {
+		return 0
+	}
+	var n int
+	leaf, i := tree.seekFirst(key)
+	for leaf != nil && leaf.kvs[i].key == key {
+		n++
+		leaf, i = tree.nextPos(leaf, i)
+	}
+	return n
+}
+
+// DeleteOne removes the first pair equal to key and value. It returns -1 if
+// no such pair exists.
+func (tree *BPlusTree) DeleteOne(key KeyType, value DataType) int {
+	leaf, i := tree.seekFirst(key)
+	for leaf != nil && leaf.kvs[i].key == key {
+		if leaf.kvs[i].value == value {
+			tree.leafRemove(leaf, i)
+			return 0
+		}
+		leaf, i = tree.nextPos(leaf, i)
+	}
+	return -1
+}
+
+// DeleteAll removes every value stored under key and returns how many were
+// removed.
+func (tree *BPlusTree) DeleteAll(key KeyType) int {
+	var n int
+	for {
+		leaf, i := tree.seekFirst(key)
+		if leaf == nil || leaf.kvs[i].key != key {
+			return n
+		}
+		tree.leafRemove(leaf, i)
+		n++
+	}
+}
+
+// nextPos steps to the entry after leaf.kvs[i], returning a nil leaf past
+// the last entry.
+func (tree *BPlusTree) nextPos(leaf *bplusLeaf, i int) (*bplusLeaf, int) {
+	if i++; i < leaf.entries {
+		return leaf, i
+	}
+	if tree.listIsLastLeaf(leaf) {
+		return nil, 0
+	}
+	return leaf.next, 0
+}
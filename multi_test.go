@@ -0,0 +1,70 @@
+package bplustree
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func TestMulti(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, cfg := range [][2]int{{3, 3}, {4, 4}, {7, 10}} {
+		tree := New(cfg[0], cfg[1], Multi())
+		model := make(map[KeyType][]DataType)
+		for i := 0; i < 5000; i++ {
+			key := r.Intn(20)
+			switch r.Intn(4) {
+			case 0:
+				vals := model[key]
+				if len(vals) == 0 {
+					if tree.DeleteOne(key, 0) != -1 {
+						t.Fatalf("%v: DeleteOne(%d) on missing key", cfg, key)
+					}
+					continue
+				}
+				j := r.Intn(len(vals))
+				if tree.DeleteOne(key, vals[j]) != 0 {
+					t.Fatalf("%v: DeleteOne(%d, %d) failed", cfg, key, vals[j])
+				}
+				model[key] = append(vals[:j:j], vals[j+1:]...)
+			case 1:
+				if r.Intn(10) == 0 {
+					if n := tree.DeleteAll(key); n != len(model[key]) {
+						t.Fatalf("%v: DeleteAll(%d) = %d, want %d", cfg, key, n, len(model[key]))
+					}
+					delete(model, key)
+				}
+			default:
+				if tree.Insert(key, i) != 0 {
+					t.Fatalf("%v: Insert(%d) rejected", cfg, key)
+				}
+				model[key] = append(model[key], i)
+			}
+
+			if got := tree.SearchAll(key); fmt.Sprint(got) != fmt.Sprint(model[key]) && len(got)+len(model[key]) > 0 {
+				t.Fatalf("%v: SearchAll(%d) = %v, want %v", cfg, key, got, model[key])
+			}
+		}
+
+		for key, vals := range model {
+			if n := tree.Count(key); n != len(vals) {
+				t.Fatalf("%v: Count(%d) = %d, want %d", cfg, key, n, len(vals))
+			}
+			if len(vals) > 0 {
+				if v, ok := tree.Search(key); !ok || v != vals[0] {
+					t.Fatalf("%v: Search(%d) = %d, %v, want %d", cfg, key, v, ok, vals[0])
+				}
+			}
+		}
+	}
+}
+
+func TestUniqueRejectsDuplicate(t *testing.T) {
+	tree := New(3, 3)
+	if tree.Insert(1, 10) != 0 || tree.Insert(1, 11) != -1 {
+		t.Fatalf("duplicate key accepted")
+	}
+	if tree.Count(1) != 1 || tree.DeleteOne(1, 11) != -1 || tree.DeleteOne(1, 10) != 0 {
+		t.Fatalf("unique tree multimap helpers")
+	}
+}
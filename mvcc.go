@@ -0,0 +1,139 @@
+package bplustree
+
+import (
+	"iter"
+	"math"
+)
+
+// mvccRecord is one version of a key, visible to reads at versions in
+// [begin, end).
+type mvccRecord struct {
+	begin, end uint64
+	value      DataType
+}
+
+/** end of a record no write has superseded yet */
+const mvccLive = math.MaxUint64
+
+// MVCCTree keeps every version of its keys for point-in-time reads. Each
+// write is stamped with a new version number. The versions of a key are
+// equal keys of a multimap tree, oldest first, whose values are ids of
+// records holding the value and the versions it was visible at.
+type MVCCTree struct {
+	tree    *BPlusTree
+	records []mvccRecord
+	/** ids of records released by GC */
+	free    []int
+	version uint64
+}
+
+// NewMVCC returns an empty MVCCTree at version 0 whose tree is created with
+// the given non-leaf order and leaf capacity.
+func NewMVCC(order int, entries int) *MVCCTree {
+	return &MVCCTree{tree: New(order, entries, Multi())}
+}
+
+// Version returns the version of the latest write, 0 before the first.
+func (m *MVCCTree) Version() uint64 {
+	return m.version
+}
+
+/** the record of key visible at version, or -1 */
+func (m *MVCCTree) visible(key KeyType, version uint64) int {
+	leaf, i := m.tree.seekFirst(key)
+	for leaf != nil && leaf.kvs[i].key == key {
+		id := leaf.kvs[i].value
+		if rec := &m.records[id]; rec.begin <= version && version < rec.end {
+			return id
+		}
+		leaf, i = m.tree.nextPos(leaf, i)
+	}
+	return -1
+}
+
+func (m *MVCCTree) newRecord(value DataType) int {
+	rec := mvccRecord{begin: m.version, end: mvccLive, value: value}
+	if n := len(m.free); n > 0 {
+		id := m.free[n-1]
+		m.free = m.free[:n-1]
+		m.records[id] = rec
+		return id
+	}
+	m.records = append(m.records, rec)
+	return len(m.records) - 1
+}
+
+// Put stores value under key as a new version and returns that version.
+func (m *MVCCTree) Put(key KeyType, value DataType) uint64 {
+	live := m.visible(key, m.version)
+	m.version++
+	if live >= 0 {
+		m.records[live].end = m.version
+	}
+	ret := m.tree.Insert(key, m.newRecord(value))
+	assert(ret == 0)
+	return m.version
+}
+
+// Delete ends the current version of key and returns the new version. It
+// returns 0 if key has no value at the latest version.
+func (m *MVCCTree) Delete(key KeyType) uint64 {
+	live := m.visible(key, m.version)
+	if live < 0 {
+		return 0
+	}
+	m.version++
+	m.records[live].end = m.version
+	return m.version
+}
+
+// Get returns the value of key as of version.
+func (m *MVCCTree) Get(key KeyType, version uint64) (DataType, bool) {
+	if id := m.visible(key, version); id >= 0 {
+		return m.records[id].value, true
+	}
+	return 0, false
+}
+
+// Ascend yields the keys between lo and hi inclusive that have a value as
+// of version, with that value, in key order.
+func (m *MVCCTree) Ascend(lo, hi KeyType, version uint64) iter.Seq2[KeyType, DataType] {
+	return func(yield func(KeyType, DataType) bool) {
+		for it := m.tree.Seek(lo); it.Valid() && it.Key() <= hi; it.Next() {
+			rec := &m.records[it.Value()]
+			if rec.begin <= version && version < rec.end {
+				if !yield(it.Key(), rec.value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GC removes the versions no read at oldest or later can see and returns
+// how many were removed. Reads at versions before oldest are no longer
+// answered correctly afterwards. Leaves left underfull are merged with or
+// refilled from their siblings as on any delete.
+func (m *MVCCTree) GC(oldest uint64) int {
+	type entry struct {
+		key KeyType
+		id  int
+	}
+	var dead []entry
+	for it := m.tree.First(); it.Valid(); it.Next() {
+		if m.records[it.Value()].end <= oldest {
+			dead = append(dead, entry{it.Key(), it.Value()})
+		}
+	}
+	for _, e := range dead {
+		ret := m.tree.DeleteOne(e.key, e.id)
+		assert(ret == 0)
+		m.free = append(m.free, e.id)
+	}
+	return len(dead)
+}
+
+// Versions returns the number of versions stored, live or superseded.
+func (m *MVCCTree) Versions() int {
+	return m.tree.Len()
+}
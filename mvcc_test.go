@@ -0,0 +1,76 @@
+package bplustree
+
+import (
+	"maps"
+	"math/rand"
+	"testing"
+)
+
+func TestMVCC(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	m := NewMVCC(3, 3)
+	/* history[v] is the expected state as of version v */
+	history := []map[KeyType]DataType{{}}
+	check := func(from uint64) {
+		for v := from; v <= m.Version(); v++ {
+			want := history[v]
+			for key := 0; key < 30; key++ {
+				got, ok := m.Get(key, v)
+				if w, exists := want[key]; ok != exists || got != w {
+					t.Fatalf("Get(%d, %d) = %d, %v, want %d, %v", key, v, got, ok, w, exists)
+				}
+			}
+			n := 0
+			for key, value := range m.Ascend(0, 29, v) {
+				if want[key] != value {
+					t.Fatalf("Ascend at %d yields %d: %d, want %d", v, key, value, want[key])
+				}
+				n++
+			}
+			if n != len(want) {
+				t.Fatalf("Ascend at %d yields %d keys, want %d", v, n, len(want))
+			}
+		}
+	}
+
+	var oldest uint64
+	for round := 0; round < 20; round++ {
+		for i := 0; i < 50; i++ {
+			key := r.Intn(30)
+			state := maps.Clone(history[len(history)-1])
+			if r.Intn(3) == 0 {
+				_, exists := state[key]
+				if v := m.Delete(key); (v != 0) != exists {
+					t.Fatalf("Delete(%d) = %d, exists %v", key, v, exists)
+				}
+				if !exists {
+					continue
+				}
+				delete(state, key)
+			} else {
+				if v := m.Put(key, i); v != uint64(len(history)) {
+					t.Fatalf("Put returned version %d, want %d", v, len(history))
+				}
+				state[key] = i
+			}
+			history = append(history, state)
+		}
+		check(oldest)
+
+		oldest += uint64(r.Intn(int(m.Version()-oldest) + 1))
+		before := m.Versions()
+		if n := m.GC(oldest); m.Versions() != before-n {
+			t.Fatalf("GC removed %d versions, count went from %d to %d", n, before, m.Versions())
+		}
+		if err := m.tree.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		check(oldest)
+	}
+
+	/* collecting up to the latest version keeps only the live values */
+	m.GC(m.Version())
+	if m.Versions() != len(history[m.Version()]) {
+		t.Fatalf("%d versions left, want %d", m.Versions(), len(history[m.Version()]))
+	}
+}
@@ -0,0 +1,76 @@
+package bplustree
+
+// KeyRange is the smallest and the greatest key held by a node's subtree.
+type KeyRange struct {
+	Min, Max KeyType
+}
+
+// SplitEvent describes a node split into Left and Right. Level is the
+// height of the split nodes, 0 for leaves.
+type SplitEvent struct {
+	Level       int
+	Left, Right KeyRange
+	/** separator promoted to the parent */
+	SplitKey KeyType
+}
+
+// BorrowEvent describes an underflowing node taking one entry or child from
+// a sibling. Level is the height of the nodes, 0 for leaves.
+type BorrowEvent struct {
+	Level         int
+	FromLeft      bool
+	Node, Sibling KeyRange
+}
+
+// MergeEvent describes an underflowing node merged with a sibling. Level is
+// the height of the nodes, 0 for leaves.
+type MergeEvent struct {
+	Level  int
+	Merged KeyRange
+}
+
+// Observer receives structural changes of a tree. Callbacks run
+// synchronously inside the mutating call and must not modify the tree.
+type Observer interface {
+	OnLeafSplit(ev SplitEvent)
+	OnInnerSplit(ev SplitEvent)
+	OnBorrow(ev BorrowEvent)
+	OnMerge(ev MergeEvent)
+	/** a new root was built, level is the new tree level */
+	OnRootGrow(level int)
+	/** the root was removed, level is the new tree level */
+	OnRootShrink(level int)
+}
+
+// WithObserver installs obs on the tree.
+func WithObserver(obs Observer) Option {
+	return func(tree *BPlusTree) {
+		tree.observer = obs
+	}
+}
+
+// SetObserver replaces the tree's observer, nil removes it.
+func (tree *BPlusTree) SetObserver(obs Observer) {
+	tree.observer = obs
+}
+
+// nodeRange returns the key range of the subtree rooted at n.
+func nodeRange(n node) KeyRange {
+	var r KeyRange
+	for lo := n; ; {
+		if leaf, ok := lo.(*bplusLeaf); ok {
+			r.Min = leaf.kvs[0].key
+			break
+		}
+		lo = lo.(*bplusNonLeaf).subPtr[0]
+	}
+	for hi := n; ; {
+		if leaf, ok := hi.(*bplusLeaf); ok {
+			r.Max = leaf.kvs[leaf.entries-1].key
+			break
+		}
+		nl := hi.(*bplusNonLeaf)
+		hi = nl.subPtr[nl.children-1]
+	}
+	return r
+}
@@ -0,0 +1,73 @@
+package bplustree
+
+import (
+	"testing"
+)
+
+type recordingObserver struct {
+	leafSplits, innerSplits, borrows, merges int
+	level                                    int
+	t                                        *testing.T
+}
+
+func (o *recordingObserver) OnLeafSplit(ev SplitEvent) {
+	o.leafSplits++
+	if ev.Level != 0 || ev.Left.Max >= ev.Right.Min || ev.SplitKey != ev.Right.Min {
+		o.t.Fatalf("leaf split: %+v", ev)
+	}
+}
+
+func (o *recordingObserver) OnInnerSplit(ev SplitEvent) {
+	o.innerSplits++
+	if ev.Level < 1 || ev.Left.Max >= ev.SplitKey || ev.SplitKey > ev.Right.Min {
+		o.t.Fatalf("inner split: %+v", ev)
+	}
+}
+
+func (o *recordingObserver) OnBorrow(ev BorrowEvent) {
+	o.borrows++
+	if ev.FromLeft && ev.Sibling.Max >= ev.Node.Min || !ev.FromLeft && ev.Node.Max >= ev.Sibling.Min {
+		o.t.Fatalf("borrow: %+v", ev)
+	}
+}
+
+func (o *recordingObserver) OnMerge(ev MergeEvent) {
+	o.merges++
+}
+
+func (o *recordingObserver) OnRootGrow(level int) {
+	if level != o.level+1 {
+		o.t.Fatalf("root grew to level %d from %d", level, o.level)
+	}
+	o.level = level
+}
+
+func (o *recordingObserver) OnRootShrink(level int) {
+	if level != o.level-1 {
+		o.t.Fatalf("root shrank to level %d from %d", level, o.level)
+	}
+	o.level = level
+}
+
+func TestObserver(t *testing.T) {
+	obs := &recordingObserver{t: t}
+	tree := New(3, 3, WithObserver(obs))
+	for i := 0; i < 200; i++ {
+		tree.Insert((i*37)%200, i)
+	}
+	for i := 0; i < 200; i++ {
+		tree.Delete((i * 91) % 200)
+	}
+
+	st := tree.Stats()
+	if uint64(obs.leafSplits) != st.LeafSplits || uint64(obs.innerSplits) != st.InnerSplits ||
+		uint64(obs.borrows) != st.LeafBorrows+st.InnerBorrows || uint64(obs.merges) != st.LeafMerges+st.InnerMerges {
+		t.Fatalf("observer %+v disagrees with stats %+v", obs, st)
+	}
+	if obs.level != tree.level || obs.leafSplits == 0 || obs.merges == 0 {
+		t.Fatalf("observer %+v, tree level %d", obs, tree.level)
+	}
+
+	tree.SetObserver(nil)
+	tree.Insert(1, 1)
+}
@@ -0,0 +1,143 @@
+package bplustree
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// Item is a key-value pair returned by Page.
+type Item struct {
+	Key   KeyType
+	Value DataType
+}
+
+// ErrBadToken is returned by Page for a token it did not issue for the
+// same scan.
+var ErrBadToken = errors.New("bplustree: malformed page token")
+
+// ErrBadLimit is returned by Page for a limit that is not positive.
+var ErrBadLimit = errors.New("bplustree: page limit must be positive")
+
+const pageTokenVersion = 1
+
+// pageToken is where a paged scan stopped: the last key returned, how
+// many pairs under it were returned, and the direction.
+type pageToken struct {
+	key  KeyType
+	skip uint64
+	desc bool
+}
+
+func (pt pageToken) encode() string {
+	b := []byte{pageTokenVersion, 0}
+	if pt.desc {
+		b[1] = 1
+	}
+	b = binary.AppendVarint(b, int64(pt.key))
+	b = binary.AppendUvarint(b, pt.skip)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageToken(token string) (pageToken, error) {
+	var pt pageToken
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) < 2 || b[0] != pageTokenVersion || b[1] > 1 {
+		return pt, ErrBadToken
+	}
+	pt.desc = b[1] == 1
+	b = b[2:]
+	key, n := binary.Varint(b)
+	if n <= 0 {
+		return pt, ErrBadToken
+	}
+	b = b[n:]
+	skip, n := binary.Uvarint(b)
+	if n <= 0 || n != len(b) || skip == 0 {
+		return pt, ErrBadToken
+	}
+	pt.key, pt.skip = KeyType(key), skip
+	return pt, nil
+}
+
+// pageStart positions an iterator at the first pair of a scan from key,
+// the greatest key not greater than key when desc is set.
+func (tree *BPlusTree) pageStart(key KeyType, desc bool) *Iterator {
+	if !desc {
+		return tree.Seek(key)
+	}
+	if key == math.MaxInt {
+		return tree.Last()
+	}
+	it := tree.Seek(key + 1)
+	if it.Valid() {
+		it.Prev()
+		return it
+	}
+	return tree.Last()
+}
+
+// Page returns up to limit pairs with keys between lo and hi inclusive,
+// ascending from lo, or descending from lo when lo is greater than hi,
+// and a token to pass with the same bounds for the next page, empty after
+// the last one. An empty token starts the scan. The token is an opaque
+// URL-safe string holding the last key returned and the direction; each
+// page descends the tree afresh to the key after it and walks the leaf
+// chain from there, so keys inserted or removed between pages are seen or
+// missed as they would be by a single scan of the current tree. Pairs
+// under equal keys of a multimap are told apart by their rank, so removing
+// one already returned makes the next page pass over one more. A limit
+// that is not positive returns ErrBadLimit.
+func (tree *BPlusTree) Page(lo, hi KeyType, limit int, token string) ([]Item, string, error) {
+	if limit <= 0 {
+		return nil, "", ErrBadLimit
+	}
+	desc := lo > hi
+	inRange := func(key KeyType) bool {
+		if desc {
+			return key >= hi && key <= lo
+		}
+		return key >= lo && key <= hi
+	}
+	step := (*Iterator).Next
+	if desc {
+		step = (*Iterator).Prev
+	}
+
+	var it *Iterator
+	var prev pageToken
+	if token == "" {
+		it = tree.pageStart(lo, desc)
+	} else {
+		var err error
+		if prev, err = decodePageToken(token); err != nil {
+			return nil, "", err
+		}
+		if prev.desc != desc || !inRange(prev.key) {
+			return nil, "", ErrBadToken
+		}
+		it = tree.pageStart(prev.key, desc)
+		/* the pairs under the last key already returned */
+		for n := uint64(0); n < prev.skip && it.Valid() && it.Key() == prev.key; n++ {
+			step(it)
+		}
+	}
+
+	var items []Item
+	for ; it.Valid() && inRange(it.Key()) && len(items) < limit; step(it) {
+		items = append(items, Item{it.Key(), it.Value()})
+	}
+	if len(items) == 0 || !it.Valid() || !inRange(it.Key()) {
+		return items, "", nil
+	}
+
+	next := pageToken{key: items[len(items)-1].Key, desc: desc}
+	for i := len(items) - 1; i >= 0 && items[i].Key == next.key; i-- {
+		next.skip++
+	}
+	if int(next.skip) == len(items) && token != "" && prev.key == next.key {
+		next.skip += prev.skip
+	}
+	return items, next.encode(), nil
+}
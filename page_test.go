@@ -0,0 +1,110 @@
+package bplustree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPage(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, multi := range []bool{false, true} {
+		var opts []Option
+		if multi {
+			opts = append(opts, Multi())
+		}
+		for round := 0; round < 100; round++ {
+			tree := New(3, 3, opts...)
+			for i := 0; i < 300; i++ {
+				tree.Insert(r.Intn(200), i)
+			}
+			lo, hi := r.Intn(220)-10, r.Intn(220)-10
+			desc := lo > hi
+			limit := 1 + r.Intn(7)
+
+			/* keys present throughout must be returned once, in scan order */
+			kept := make(map[Item]int)
+			for it := tree.First(); it.Valid(); it.Next() {
+				kept[Item{it.Key(), it.Value()}]++
+			}
+			var token string
+			var got []Item
+			for pages := 0; ; pages++ {
+				items, next, err := tree.Page(lo, hi, limit, token)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(items) > limit || next != "" && len(items) != limit {
+					t.Fatalf("round %d: page of %d items for limit %d", round, len(items), limit)
+				}
+				got = append(got, items...)
+				if token = next; token == "" {
+					break
+				}
+				for i := 0; i < 3; i++ {
+					k := r.Intn(200)
+					/* removing an equal key already returned shifts the ranks */
+					if multi && k == items[len(items)-1].Key {
+						continue
+					}
+					if r.Intn(2) == 0 {
+						if v, ok := tree.Search(k); ok {
+							kept[Item{k, v}] = 0
+							tree.Delete(k)
+						}
+					} else if tree.Insert(k, -1) == 0 {
+						kept[Item{k, -1}] = 0
+					}
+				}
+			}
+
+			for i, item := range got {
+				if desc && (item.Key > lo || item.Key < hi) || !desc && (item.Key < lo || item.Key > hi) {
+					t.Fatalf("round %d: key %d out of range", round, item.Key)
+				}
+				if i > 0 {
+					prev := got[i-1].Key
+					if desc && item.Key > prev || !desc && item.Key < prev || !multi && item.Key == prev {
+						t.Fatalf("round %d: key %d after %d", round, item.Key, prev)
+					}
+				}
+				if kept[item] > 0 {
+					kept[item]--
+				}
+			}
+			for item, n := range kept {
+				if inRange := desc && item.Key <= lo && item.Key >= hi || !desc && item.Key >= lo && item.Key <= hi; n > 0 && inRange {
+					t.Fatalf("round %d multi=%v: %v missed", round, multi, item)
+				}
+			}
+		}
+	}
+}
+
+func TestPageToken(t *testing.T) {
+	tree := New(3, 3)
+	for k := 0; k < 10; k++ {
+		tree.Insert(k, k)
+	}
+	items, next, err := tree.Page(9, 0, 4, "")
+	if err != nil || len(items) != 4 || items[0].Key != 9 || items[3].Key != 6 {
+		t.Fatalf("first page %v, %v", items, err)
+	}
+	if _, _, err := tree.Page(0, 9, 4, next); err != ErrBadToken {
+		t.Fatal("token accepted for the other direction")
+	}
+	if _, _, err := tree.Page(9, 0, 4, next[:len(next)-1]+"!"); err != ErrBadToken {
+		t.Fatal("corrupt token accepted")
+	}
+	items, next, _ = tree.Page(9, 0, 4, next)
+	if len(items) != 4 || items[0].Key != 5 {
+		t.Fatalf("second page %v", items)
+	}
+	if items, next, _ = tree.Page(9, 0, 4, next); len(items) != 2 || next != "" {
+		t.Fatalf("last page %v, token %q", items, next)
+	}
+	for _, limit := range []int{0, -1} {
+		if _, _, err := tree.Page(0, 9, limit, ""); err != ErrBadLimit {
+			t.Fatalf("limit %d: %v", limit, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package bplustree
+
+import (
+	"bytes"
+	"iter"
+)
+
+// prefixEnd returns the smallest key greater than every key starting with
+// prefix, or nil when no such key exists (the prefix is empty or all 0xFF).
+func prefixEnd(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] != 0xff {
+			end := make([]byte, i+1)
+			copy(end, prefix)
+			end[i]++
+			return end
+		}
+	}
+	return nil
+}
+
+// scan calls fn for every key in [start, end) in ascending order until fn
+// returns false. A nil end means no upper bound.
+func (tree *BytesTree) scan(start, end []byte, fn func(key []byte, value DataType) bool) {
+	for it := tree.Seek(start); it.Valid(); it.Next() {
+		key := it.Key()
+		if end != nil && bytes.Compare(key, end) >= 0 || !fn(key, it.Value()) {
+			return
+		}
+	}
+}
+
+// ScanPrefix calls fn for every key starting with prefix in ascending order
+// until fn returns false.
+func (tree *BytesTree) ScanPrefix(prefix []byte, fn func(key []byte, value DataType) bool) {
+	tree.scan(prefix, prefixEnd(prefix), fn)
+}
+
+// PrefixSeq returns an iterator over the keys starting with prefix in
+// ascending order.
+func (tree *BytesTree) PrefixSeq(prefix []byte) iter.Seq2[[]byte, DataType] {
+	return func(yield func([]byte, DataType) bool) {
+		tree.ScanPrefix(prefix, yield)
+	}
+}
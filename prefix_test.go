@@ -0,0 +1,58 @@
+package bplustree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScanPrefix(t *testing.T) {
+	tree := NewBytes(128)
+	for i := 0; i < 50; i++ {
+		tree.Insert([]byte(fmt.Sprintf("tenant%d/%02d", i%5, i)), i)
+	}
+	tree.Insert([]byte("tenant4"), -1)
+	tree.Insert([]byte("tenant4\xff"), -2)
+	tree.Insert([]byte("tenant4\xff\xff"), -3)
+	tree.Insert([]byte("tenant5"), -4)
+
+	var got []string
+	tree.ScanPrefix([]byte("tenant4/"), func(key []byte, value DataType) bool {
+		got = append(got, string(key))
+		return true
+	})
+	if len(got) != 10 || got[0] != "tenant4/04" || got[9] != "tenant4/49" {
+		t.Fatalf("ScanPrefix(tenant4/) = %q", got)
+	}
+
+	got = got[:0]
+	for key := range tree.PrefixSeq([]byte("tenant4\xff")) {
+		got = append(got, string(key))
+	}
+	if fmt.Sprint(got) != fmt.Sprint([]string{"tenant4\xff", "tenant4\xff\xff"}) {
+		t.Fatalf("PrefixSeq(tenant4\\xff) = %q", got)
+	}
+
+	n := 0
+	for range tree.PrefixSeq(nil) {
+		if n++; n == 3 {
+			break
+		}
+	}
+	if n != 3 {
+		t.Fatalf("PrefixSeq did not stop early")
+	}
+}
+
+func TestPrefixEnd(t *testing.T) {
+	for _, c := range [][2]string{
+		{"abc", "abd"},
+		{"ab\xff", "ac"},
+		{"a\xff\xff", "b"},
+		{"\xff\xff", ""},
+		{"", ""},
+	} {
+		if got := string(prefixEnd([]byte(c[0]))); got != c[1] {
+			t.Fatalf("prefixEnd(%q) = %q, want %q", c[0], got, c[1])
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package bplustree
+
+import (
+	"iter"
+)
+
+// Resolver picks the value of a key present in both trees of a Union or an
+// Intersect. A nil Resolver keeps the value of the first tree.
+type Resolver func(key KeyType, a, b DataType) DataType
+
+// mergeJoin walks the leaf chains of a and b in lockstep and calls fn for
+// every key of either tree, in key order, with the side or sides it was
+// found in. Equal keys of multimaps are matched pairwise in order, the
+// surplus of one side being reported as found in that side only.
+func mergeJoin(a, b *BPlusTree, fn func(key KeyType, va, vb DataType, inA, inB bool) bool) {
+	ia, ib := a.First(), b.First()
+	for ia.Valid() || ib.Valid() {
+		switch {
+		case !ib.Valid() || ia.Valid() && ia.Key() < ib.Key():
+			if !fn(ia.Key(), ia.Value(), 0, true, false) {
+				return
+			}
+			ia.Next()
+		case !ia.Valid() || ib.Key() < ia.Key():
+			if !fn(ib.Key(), 0, ib.Value(), false, true) {
+				return
+			}
+			ib.Next()
+		default:
+			if !fn(ia.Key(), ia.Value(), ib.Value(), true, true) {
+				return
+			}
+			ia.Next()
+			ib.Next()
+		}
+	}
+}
+
+func (resolve Resolver) value(key KeyType, va, vb DataType) DataType {
+	if resolve == nil {
+		return va
+	}
+	return resolve(key, va, vb)
+}
+
+// Union yields the keys of a or b in key order. Keys in both trees get the
+// value chosen by resolve. Pass the result to BulkLoad to materialize it.
+func Union(a, b *BPlusTree, resolve Resolver) iter.Seq2[KeyType, DataType] {
+	return func(yield func(KeyType, DataType) bool) {
+		mergeJoin(a, b, func(key KeyType, va, vb DataType, inA, inB bool) bool {
+			switch {
+			case inA && inB:
+				return yield(key, resolve.value(key, va, vb))
+			case inA:
+				return yield(key, va)
+			default:
+				return yield(key, vb)
+			}
+		})
+	}
+}
+
+// Intersect yields the keys present in both a and b in key order, with the
+// value chosen by resolve.
+func Intersect(a, b *BPlusTree, resolve Resolver) iter.Seq2[KeyType, DataType] {
+	return func(yield func(KeyType, DataType) bool) {
+		mergeJoin(a, b, func(key KeyType, va, vb DataType, inA, inB bool) bool {
+			if inA && inB {
+				return yield(key, resolve.value(key, va, vb))
+			}
+			return true
+		})
+	}
+}
+
+// Difference yields the keys of a that are not in b in key order, with
+// their values in a.
+func Difference(a, b *BPlusTree) iter.Seq2[KeyType, DataType] {
+	return func(yield func(KeyType, DataType) bool) {
+		mergeJoin(a, b, func(key KeyType, va, vb DataType, inA, inB bool) bool {
+			if inA && !inB {
+				return yield(key, va)
+			}
+			return true
+		})
+	}
+}
+
+// SymmetricDifference yields the keys present in exactly one of a and b in
+// key order, with their values in that tree.
+func SymmetricDifference(a, b *BPlusTree) iter.Seq2[KeyType, DataType] {
+	return func(yield func(KeyType, DataType) bool) {
+		mergeJoin(a, b, func(key KeyType, va, vb DataType, inA, inB bool) bool {
+			switch {
+			case inA && inB:
+				return true
+			case inA:
+				return yield(key, va)
+			default:
+				return yield(key, vb)
+			}
+		})
+	}
+}
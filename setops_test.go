@@ -0,0 +1,106 @@
+package bplustree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestSetOps(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a, b := New(3, 3), New(4, 6)
+	ma, mb := make(map[KeyType]DataType), make(map[KeyType]DataType)
+	for i := 0; i < 500; i++ {
+		if k := r.Intn(1000); a.Insert(k, k) == 0 {
+			ma[k] = k
+		}
+		if k := r.Intn(1000); b.Insert(k, -k) == 0 {
+			mb[k] = -k
+		}
+	}
+	sum := func(key KeyType, va, vb DataType) DataType { return va + vb + 1 }
+
+	for _, op := range []struct {
+		name string
+		seq  func(yield func(KeyType, DataType) bool)
+		want func(k KeyType) (DataType, bool)
+	}{
+		{"Union", Union(a, b, sum), func(k KeyType) (DataType, bool) {
+			va, inA := ma[k]
+			vb, inB := mb[k]
+			if inA && inB {
+				return sum(k, va, vb), true
+			} else if inA {
+				return va, true
+			}
+			return vb, inB
+		}},
+		{"Intersect", Intersect(a, b, nil), func(k KeyType) (DataType, bool) {
+			_, inB := mb[k]
+			va, inA := ma[k]
+			return va, inA && inB
+		}},
+		{"Difference", Difference(a, b), func(k KeyType) (DataType, bool) {
+			_, inB := mb[k]
+			va, inA := ma[k]
+			return va, inA && !inB
+		}},
+		{"SymmetricDifference", SymmetricDifference(a, b), func(k KeyType) (DataType, bool) {
+			va, inA := ma[k]
+			vb, inB := mb[k]
+			if inA && !inB {
+				return va, true
+			}
+			return vb, inB && !inA
+		}},
+	} {
+		tree, err := BulkLoad(5, 5, op.seq)
+		if err != nil {
+			t.Fatalf("%s: %v", op.name, err)
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatalf("%s: %v", op.name, err)
+		}
+		n := 0
+		for k := 0; k < 1000; k++ {
+			want, ok := op.want(k)
+			if !ok {
+				want = 0
+			}
+			got, found := tree.Search(k)
+			if ok != found || got != want {
+				t.Fatalf("%s: key %d = %d, %v, want %d, %v", op.name, k, got, found, want, ok)
+			}
+			if ok {
+				n++
+			}
+		}
+		if tree.Len() != n {
+			t.Fatalf("%s: %d keys, want %d", op.name, tree.Len(), n)
+		}
+	}
+}
+
+func TestSetOpsMulti(t *testing.T) {
+	a, b := New(3, 3, Multi()), New(3, 3, Multi())
+	for _, k := range []KeyType{1, 1, 1, 2, 3} {
+		a.Insert(k, k)
+	}
+	for _, k := range []KeyType{1, 3, 3, 4} {
+		b.Insert(k, k)
+	}
+	var got []KeyType
+	for k := range Difference(a, b) {
+		got = append(got, k)
+	}
+	/* equal keys are matched pairwise */
+	if len(got) != 3 || got[0] != 1 || got[1] != 1 || got[2] != 2 {
+		t.Fatalf("Difference = %v", got)
+	}
+	got = got[:0]
+	for k := range Intersect(a, b, nil) {
+		got = append(got, k)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("Intersect = %v", got)
+	}
+}
@@ -0,0 +1,537 @@
+package bplustree
+
+import (
+	"fmt"
+)
+
+// subtree is a root node and its height, 0 for a leaf.
+type subtree struct {
+	root  node
+	level int
+}
+
+// subtreeMin returns the smallest key of the subtree rooted at n.
+func subtreeMin(n node) KeyType {
+	for {
+		if leaf, ok := n.(*bplusLeaf); ok {
+			return leaf.kvs[0].key
+		}
+		n = n.(*bplusNonLeaf).subPtr[0]
+	}
+}
+
+// levelHeads returns the leftmost non-leaf node of every level of the tree,
+// indexed by height.
+func (tree *BPlusTree) levelHeads() []*bplusNonLeaf {
+	heads := make([]*bplusNonLeaf, tree.level+1)
+	n := tree.root
+	for h := tree.level; h > 0; h-- {
+		nl := n.(*bplusNonLeaf)
+		heads[h] = nl
+		n = nl.subPtr[0]
+	}
+	return heads
+}
+
+// eachLeaf calls fn for every leaf of the ring starting at first.
+func eachLeaf(first *bplusLeaf, fn func(leaf *bplusLeaf)) {
+	if first == nil {
+		return
+	}
+	for leaf := first; ; leaf = leaf.next {
+		fn(leaf)
+		if leaf.next == first {
+			return
+		}
+	}
+}
+
+/** make ring b follow ring a, a and b being the heads of their rings */
+func spliceLeaves(a, b *bplusLeaf) {
+	aTail, bTail := a.prev, b.prev
+	aTail.next, b.prev = b, aTail
+	bTail.next, a.prev = a, bTail
+}
+
+func spliceNonLeaves(a, b *bplusNonLeaf) {
+	aTail, bTail := a.prev, b.prev
+	aTail.next, b.prev = b, aTail
+	bTail.next, a.prev = a, bTail
+}
+
+// rebalance brings the non-root node n at the given level back to its fill
+// bound, merging it with its sibling under the same parent when both fit in
+// one node and moving pairs or children over from the sibling otherwise.
+// Unlike the rebalancing of Delete, n may be short by more than one.
+func (tree *BPlusTree) rebalance(n node, level int) {
+	if leaf, ok := n.(*bplusLeaf); ok {
+		tree.rebalanceLeaf(leaf)
+	} else {
+		tree.rebalanceNonLeaf(n.(*bplusNonLeaf), level)
+	}
+}
+
+func (tree *BPlusTree) rebalanceLeaf(leaf *bplusLeaf) {
+	if leaf.entries >= (tree.entries+1)/2 {
+		tree.touch(leaf)
+		return
+	}
+	parent := leaf.parent
+	left, right, fromLeft := leaf, leaf.next, false
+	if leaf.parentKeyIdx >= 0 {
+		left, right, fromLeft = leaf.prev, leaf, true
+	}
+	i := right.parentKeyIdx
+	if left.entries+right.entries <= tree.entries {
+		left.mergeFromRight(right)
+		tree.counters.leafMerges++
+		if tree.observer != nil {
+			tree.observer.OnMerge(MergeEvent{Merged: nodeRange(left)})
+		}
+		/* trace upwards */
+		tree.nonLeafRemove(parent, i, 1)
+		tree.touch(left)
+		return
+	}
+	/* even out the pair, both halves of more than a full leaf reach the bound */
+	total := left.entries + right.entries
+	for left.entries != (total+1)/2 {
+		if left.entries < (total+1)/2 {
+			left.shiftFromRight(right, i)
+		} else {
+			right.shiftFromLeft(left, i, right.entries)
+			right.entries++
+		}
+		tree.counters.leafBorrows++
+		if tree.observer != nil {
+			sib := left
+			if leaf == left {
+				sib = right
+			}
+			tree.observer.OnBorrow(BorrowEvent{FromLeft: fromLeft, Node: nodeRange(leaf), Sibling: nodeRange(sib)})
+		}
+	}
+	tree.touch(left)
+	tree.touch(right)
+}
+
+func (tree *BPlusTree) rebalanceNonLeaf(nl *bplusNonLeaf, level int) {
+	if nl.children >= (tree.order+1)/2 {
+		tree.touch(nl)
+		return
+	}
+	parent := nl.parent
+	left, right, fromLeft := nl, nl.next, false
+	if nl.parentKeyIdx >= 0 {
+		left, right, fromLeft = nl.prev, nl, true
+	}
+	i := right.parentKeyIdx
+	if left.children+right.children <= tree.order {
+		left.mergeFromRight(right, i)
+		left.recount()
+		tree.counters.innerMerges++
+		if tree.observer != nil {
+			tree.observer.OnMerge(MergeEvent{Level: level, Merged: nodeRange(left)})
+		}
+		/* trace upwards */
+		tree.nonLeafRemove(parent, i, level+1)
+		tree.touch(left)
+		return
+	}
+	total := left.children + right.children
+	for left.children != (total+1)/2 {
+		if left.children < (total+1)/2 {
+			left.shiftFromRight(right, i)
+		} else {
+			right.shiftFromLeft(left, i, right.children-1)
+			right.children++
+		}
+		tree.counters.innerBorrows++
+		if tree.observer != nil {
+			sib := left
+			if nl == left {
+				sib = right
+			}
+			tree.observer.OnBorrow(BorrowEvent{Level: level, FromLeft: fromLeft, Node: nodeRange(nl), Sibling: nodeRange(sib)})
+		}
+	}
+	left.recount()
+	right.recount()
+	left.stale()
+	right.stale()
+	tree.touch(left)
+	tree.touch(right)
+}
+
+// joinNodes joins the subtrees a and b, every key of a ordering before the
+// keys of b, and returns the joined subtree. Their nodes must already sit in
+// the level rings of tree in key order. The shorter subtree is hung off the
+// facing spine of the taller one at its own height and rebalanced with its
+// new sibling, splits and merges propagating up as for Insert and Delete.
+// A root built on top of both, or by a split reaching the root, is linked
+// into its level ring by linkRoot.
+func (tree *BPlusTree) joinNodes(a, b subtree, prev func(level int) *bplusNonLeaf) subtree {
+	sep := subtreeMin(b.root)
+	switch {
+	case a.level == b.level:
+		tree.root, tree.level = a.root, a.level
+		tree.parentNodeBuild(a.root, b.root, sep, a.level)
+		tree.linkRoot(a.level, prev)
+		/* both cannot fall short without fitting in one node */
+		if leaf, ok := a.root.(*bplusLeaf); ok && leaf.entries < (tree.entries+1)/2 {
+			tree.rebalance(a.root, a.level)
+		} else if nl, ok := a.root.(*bplusNonLeaf); ok && nl.children < (tree.order+1)/2 {
+			tree.rebalance(a.root, a.level)
+		} else {
+			tree.rebalance(b.root, b.level)
+		}
+	case a.level > b.level:
+		tree.root, tree.level = a.root, a.level
+		nl := a.root.(*bplusNonLeaf)
+		for h := a.level; h > b.level+1; h-- {
+			nl = nl.subPtr[nl.children-1].(*bplusNonLeaf)
+		}
+		/* the ancestors of the new sibling count its pairs before a split recounts them */
+		resize(nl.subPtr[nl.children-1], subtreeSize(b.root))
+		tree.parentNodeBuild(nl.subPtr[nl.children-1], b.root, sep, b.level)
+		tree.linkRoot(a.level, prev)
+		tree.rebalance(b.root, b.level)
+	default:
+		tree.root, tree.level = b.root, b.level
+		nl := b.root.(*bplusNonLeaf)
+		for h := b.level; h > a.level+1; h-- {
+			nl = nl.subPtr[0].(*bplusNonLeaf)
+		}
+		resize(nl.subPtr[0], subtreeSize(a.root))
+		tree.parentNodeBuild(a.root, nl.subPtr[0], sep, a.level)
+		tree.linkRoot(b.level, prev)
+		tree.rebalance(a.root, a.level)
+	}
+	return subtree{tree.root, tree.level}
+}
+
+// linkRoot links the root of tree into its level ring after prev(level)
+// when the root grew above level, prev is given and returns a node.
+func (tree *BPlusTree) linkRoot(level int, prev func(level int) *bplusNonLeaf) {
+	if tree.level == level || prev == nil {
+		return
+	}
+	if p := prev(tree.level); p != nil {
+		p.listAdd(tree.root.(*bplusNonLeaf), p.next)
+	}
+}
+
+// splitPiece detaches the non-leaf nl, left with the children of one side
+// of a split, and returns the subtree they form: nl itself, its only child,
+// or nothing. Dropped nodes leave their level ring.
+func splitPiece(nl *bplusNonLeaf, level int) (subtree, bool) {
+	nl.parent, nl.parentKeyIdx = nil, -1
+	nl.stale()
+	switch nl.children {
+	case 0:
+		nl.delete()
+		return subtree{}, false
+	case 1:
+		child := nl.subPtr[0]
+		nl.subPtr[0] = nil
+		bn := getNode(child)
+		bn.parent, bn.parentKeyIdx = nil, -1
+		nl.delete()
+		return subtree{child, level - 1}, true
+	}
+	return subtree{nl, level}, true
+}
+
+// emptyCopy returns an empty tree with the shape and options of tree. Its
+// change log, if any, starts afresh with the same retention.
+func (tree *BPlusTree) emptyCopy() *BPlusTree {
+	cp := New(tree.order, tree.entries)
+	cp.multi, cp.merkle, cp.monoid = tree.multi, tree.merkle, tree.monoid
+	cp.clock, cp.observer = tree.clock, tree.observer
+	if tree.changes != nil {
+		WithChanges(len(tree.changes.buf))(cp)
+	}
+	if l := tree.limit; l != nil {
+		WithLimit(l.maxItems, l.maxBytes, l.policy)(cp)
+		cp.limit.onEvict = l.onEvict
+	}
+	return cp
+}
+
+// SplitAt cuts the tree in two at key: tree keeps the keys less than key
+// and is returned as left, the others move to a new tree with the same
+// order, capacity and options returned as right. Every node on the path to
+// key is cut in two, each level ring being cut between the halves, and
+// the pieces on either side are joined back into one tree, which takes
+// O(log n) node operations; the entry counts of the halves follow from the
+// subtree sizes kept in the non-leaf nodes. When tree records changes the
+// moved pairs are recorded as deleted, and right starts its own change log
+// with none. Recording them and carrying the TTL deadlines over walks the
+// leaves of right, and carrying the use order of EvictLRU over walks the
+// whole use list, so these options make SplitAt O(n).
+func (tree *BPlusTree) SplitAt(key KeyType) (left, right *BPlusTree) {
+	right = tree.emptyCopy()
+	if tree.root == nil {
+		return tree, right
+	}
+	tree.version++
+	tree.mods++
+
+	/* the path to the first key not less than key, and where to cut it */
+	heads := tree.levelHeads()
+	path := make([]*bplusNonLeaf, tree.level+1)
+	cuts := make([]int, tree.level+1)
+	n := tree.root
+	for h := tree.level; h > 0; h-- {
+		nl := n.(*bplusNonLeaf)
+		path[h], cuts[h] = nl, nl.keyLowerSearch(key)
+		n = nl.subPtr[cuts[h]]
+	}
+
+	/* leaf level: the new sibling heads the ring of right */
+	leaf := n.(*bplusLeaf)
+	sibling := leafNew()
+	leaf.listAdd(sibling, leaf.next)
+	head, tail := tree.firstLeaf, tree.firstLeaf.prev
+	leaf.next, head.prev = head, leaf
+	tail.next, sibling.prev = sibling, tail
+	i := leaf.keyLowerSearch(key)
+	sibling.entries = copy(sibling.kvs[:], leaf.kvs[i:leaf.entries])
+	leaf.entries = i
+	leaf.parent, leaf.parentKeyIdx = nil, -1
+	leaf.stale()
+
+	var lefts, rights []subtree
+	leftFirst, rightFirst := tree.firstLeaf, sibling
+	if leaf.entries > 0 {
+		lefts = append(lefts, subtree{leaf, 0})
+	} else {
+		if leaf == leftFirst {
+			leftFirst = nil
+		}
+		leaf.delete()
+	}
+	if sibling.entries > 0 {
+		rights = append(rights, subtree{sibling, 0})
+	} else {
+		if rightFirst = sibling.next; rightFirst == sibling {
+			rightFirst = nil
+		}
+		sibling.delete()
+	}
+
+	/* non-leaf levels: the children left and right of the path */
+	ltails := make([]*bplusNonLeaf, tree.level+2)
+	rheads := make([]*bplusNonLeaf, tree.level+2)
+	for h := 1; h <= tree.level; h++ {
+		nl, c := path[h], cuts[h]
+		sib := nonLeafNew()
+		nl.listAdd(sib, nl.next)
+		head, tail := heads[h], heads[h].prev
+		nl.next, head.prev = head, nl
+		tail.next, sib.prev = sib, tail
+
+		sib.children = copy(sib.subPtr[:], nl.subPtr[c+1:nl.children])
+		if sib.children > 0 {
+			copy(sib.key[:], nl.key[c+1:nl.children-1])
+		}
+		for j := 0; j < sib.children; j++ {
+			bn := getNode(sib.subPtr[j])
+			bn.parent, bn.parentKeyIdx = sib, j-1
+		}
+		for j := c; j < nl.children; j++ {
+			// for gc
+			nl.subPtr[j] = nil
+		}
+		nl.children = c
+		nl.recount()
+		sib.recount()
+
+		/* the ends of the rings facing the cut, nodes dropped below leave them */
+		if nl.children >= 2 {
+			ltails[h] = nl
+		} else if nl != heads[h] {
+			ltails[h] = nl.prev
+		}
+		if sib.children >= 2 {
+			rheads[h] = sib
+		} else if sib.next != sib {
+			rheads[h] = sib.next
+		}
+		if st, ok := splitPiece(nl, h); ok {
+			lefts = append(lefts, st)
+		}
+		if st, ok := splitPiece(sib, h); ok {
+			rights = append(rights, st)
+		}
+	}
+
+	/*
+	 * Pieces higher up lie further from the cut. Joining from the cut
+	 * outwards, the nodes of a level above the joined pieces all belong
+	 * to pieces not joined yet, so a new root goes at the end of its
+	 * level ring facing the cut.
+	 */
+	tree.root, tree.level = nil, 0
+	ltail := func(level int) *bplusNonLeaf {
+		return ltails[min(level, len(ltails)-1)]
+	}
+	for j, st := range lefts {
+		if j == 0 {
+			tree.root, tree.level = st.root, st.level
+		} else {
+			tree.joinNodes(st, subtree{tree.root, tree.level}, ltail)
+		}
+	}
+	rtail := func(level int) *bplusNonLeaf {
+		if head := rheads[min(level, len(rheads)-1)]; head != nil {
+			return head.prev
+		}
+		return nil
+	}
+	for j, st := range rights {
+		if j == 0 {
+			right.root, right.level = st.root, st.level
+		} else {
+			right.joinNodes(subtree{right.root, right.level}, st, rtail)
+		}
+	}
+	tree.firstLeaf, right.firstLeaf = leftFirst, rightFirst
+	if tree.root == nil {
+		tree.firstLeaf = nil
+	}
+
+	if right.root != nil {
+		right.count = subtreeSize(right.root)
+	}
+	tree.count -= right.count
+	if tree.changes != nil || tree.ttl != nil {
+		eachLeaf(right.firstLeaf, func(leaf *bplusLeaf) {
+			for j := 0; j < leaf.entries; j++ {
+				k := leaf.kvs[j].key
+				if tree.changes != nil {
+					tree.changes.add(ChangeDelete, k, leaf.kvs[j].value, 0)
+				}
+				if d := tree.deadline(k); d != 0 {
+					tree.forget(k)
+					right.setDeadline(k, d)
+				}
+			}
+		})
+	}
+	if l := tree.limit; l != nil && l.lru != nil {
+		/* from the least recently used, so that right keeps the order */
+		for e := l.lru.Back(); e != nil; {
+			prev := e.Prev()
+			if k := e.Value.(KeyType); k >= key {
+				tree.unused(k)
+				right.used(k)
+			}
+			e = prev
+		}
+	}
+	right.version++
+	return tree, right
+}
+
+// Join moves every pair of right into left and returns left, right being
+// left empty. The trees must share order, leaf capacity, Multi and
+// WithMerkle, and either both or neither must have WithAggregate. The keys
+// of left must be less than the keys of right, or not greater for
+// multimaps. The level rings of right are spliced after those of left and
+// the shorter tree is hung off the facing spine of the taller one, which
+// takes O(log n) node operations. When either tree records changes the
+// moved pairs are recorded as deleted from right and inserted into left.
+// TTL deadlines move along with the pairs, the moved keys count as used
+// before every key of left, and a bounded left evicts what no longer fits.
+// The moved pairs are aggregated with the monoid of left; unless right
+// shares it, as the halves of SplitAt do, every cached aggregate of right
+// is dropped, which takes O(n) in the size of right.
+func Join(left, right *BPlusTree) (*BPlusTree, error) {
+	if left.order != right.order || left.entries != right.entries || left.multi != right.multi ||
+		left.merkle != right.merkle || (left.monoid == nil) != (right.monoid == nil) {
+		return nil, fmt.Errorf("bplustree: join of trees of different shape")
+	}
+	if right.root == nil {
+		return left, nil
+	}
+	if left.root != nil {
+		last := left.firstLeaf.prev
+		lo, hi := last.kvs[last.entries-1].key, right.firstLeaf.kvs[0].key
+		if lo > hi || lo == hi && !left.multi {
+			return nil, fmt.Errorf("bplustree: join of key %d before key %d", lo, hi)
+		}
+	}
+	if left.changes != nil || right.changes != nil {
+		eachLeaf(right.firstLeaf, func(leaf *bplusLeaf) {
+			for i := 0; i < leaf.entries; i++ {
+				k, v := leaf.kvs[i].key, leaf.kvs[i].value
+				if right.changes != nil {
+					right.changes.add(ChangeDelete, k, v, 0)
+				}
+				if left.changes != nil {
+					left.changes.add(ChangeInsert, k, 0, v)
+				}
+			}
+		})
+	}
+	if left.monoid != right.monoid {
+		/* the cached aggregates of right were combined by another monoid */
+		eachLeaf(right.firstLeaf, func(leaf *bplusLeaf) {
+			leaf.aggregated = false
+		})
+		for _, head := range right.levelHeads()[1:] {
+			for nl := head; ; nl = nl.next {
+				nl.aggregated = false
+				if nl.next == head {
+					break
+				}
+			}
+		}
+	}
+	/* per-key state moves with the pairs, before the leaf rings are spliced */
+	if right.ttl != nil {
+		for k, d := range right.ttl.deadlines {
+			left.setDeadline(k, d)
+		}
+		right.ttl = nil
+	}
+	if l := left.limit; l != nil && l.lru != nil {
+		if r := right.limit; r != nil && r.lru != nil {
+			for e := r.lru.Front(); e != nil; e = e.Next() {
+				k := e.Value.(KeyType)
+				l.elems[k] = l.lru.PushBack(k)
+			}
+		} else {
+			eachLeaf(right.firstLeaf, func(leaf *bplusLeaf) {
+				for i := 0; i < leaf.entries; i++ {
+					k := leaf.kvs[i].key
+					l.elems[k] = l.lru.PushBack(k)
+				}
+			})
+		}
+	}
+	if r := right.limit; r != nil && r.lru != nil {
+		r.lru.Init()
+		clear(r.elems)
+	}
+	left.version++
+	right.version++
+	left.mods++
+	right.mods++
+
+	if left.root == nil {
+		left.root, left.level, left.firstLeaf = right.root, right.level, right.firstLeaf
+	} else {
+		spliceLeaves(left.firstLeaf, right.firstLeaf)
+		lh, rh := left.levelHeads(), right.levelHeads()
+		for h := 1; h <= left.level && h <= right.level; h++ {
+			spliceNonLeaves(lh[h], rh[h])
+		}
+		left.joinNodes(subtree{left.root, left.level}, subtree{right.root, right.level}, nil)
+	}
+	left.count += right.count
+	right.root, right.level, right.count, right.firstLeaf = nil, 0, 0, nil
+	left.evict()
+	return left, nil
+}
@@ -0,0 +1,176 @@
+package bplustree
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSplitJoin(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, shape := range [][2]int{{3, 3}, {4, 6}, {5, 5}} {
+		for _, multi := range []bool{false, true} {
+			opts := []Option{WithMerkle(), WithAggregate(Sum)}
+			if multi {
+				opts = append(opts, Multi())
+			}
+			for round := 0; round < 200; round++ {
+				tree := New(shape[0], shape[1], opts...)
+				for i, n := 0, r.Intn(600); i < n; i++ {
+					tree.Insert(r.Intn(400), i)
+				}
+				before := treeContents(tree, false)
+				hash := tree.RootHash()
+				sum := tree.Aggregate(math.MinInt, math.MaxInt)
+				total := tree.Len()
+				key := r.Intn(420) - 10
+
+				left, right := tree.SplitAt(key)
+				for _, half := range []*BPlusTree{left, right} {
+					if err := half.Verify(); err != nil {
+						t.Fatalf("%v multi=%v round %d: split at %d: %v", shape, multi, round, key, err)
+					}
+				}
+				if left.Len()+right.Len() != total {
+					t.Fatalf("round %d: halves hold %d and %d of %d", round, left.Len(), right.Len(), total)
+				}
+				for it := left.First(); it.Valid(); it.Next() {
+					if it.Key() >= key {
+						t.Fatalf("round %d: key %d left of %d", round, it.Key(), key)
+					}
+				}
+				for it := right.First(); it.Valid(); it.Next() {
+					if it.Key() < key {
+						t.Fatalf("round %d: key %d right of %d", round, it.Key(), key)
+					}
+				}
+
+				joined, err := Join(left, right)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := joined.Verify(); err != nil {
+					t.Fatalf("%v multi=%v round %d: join at %d: %v", shape, multi, round, key, err)
+				}
+				if treeContents(joined, false) != before || joined.RootHash() != hash ||
+					joined.Aggregate(math.MinInt, math.MaxInt) != sum {
+					t.Fatalf("round %d: join does not restore the tree", round)
+				}
+				if right.Len() != 0 || right.Verify() != nil {
+					t.Fatalf("round %d: right not emptied", round)
+				}
+
+				/* the joined tree keeps working */
+				for i := 0; i < 50; i++ {
+					joined.Insert(r.Intn(400), i)
+					joined.Delete(r.Intn(400))
+				}
+				if err := joined.Verify(); err != nil {
+					t.Fatalf("round %d: after join: %v", round, err)
+				}
+			}
+		}
+	}
+}
+
+func TestJoinHeights(t *testing.T) {
+	for _, sizes := range [][2]int{{1, 500}, {500, 1}, {7, 300}, {300, 7}, {40, 40}, {0, 9}} {
+		left, right := New(3, 3), New(3, 3)
+		for i := 0; i < sizes[0]; i++ {
+			left.Insert(i, i)
+		}
+		for i := 0; i < sizes[1]; i++ {
+			right.Insert(1000+i, i)
+		}
+		tree, err := Join(left, right)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tree.Verify(); err != nil {
+			t.Fatalf("%v: %v", sizes, err)
+		}
+		if tree.Len() != sizes[0]+sizes[1] {
+			t.Fatalf("%v: %d keys", sizes, tree.Len())
+		}
+	}
+
+	left, right := New(3, 3), New(3, 3)
+	left.Insert(5, 5)
+	right.Insert(5, 5)
+	if _, err := Join(left, right); err == nil {
+		t.Fatal("Join of overlapping trees succeeded")
+	}
+	if _, err := Join(left, New(4, 3)); err == nil {
+		t.Fatal("Join of different orders succeeded")
+	}
+
+	/* the moved pairs are aggregated with the monoid of left */
+	left, right = New(3, 3, WithAggregate(Sum)), New(3, 3, WithAggregate(Max))
+	for i := 0; i < 100; i++ {
+		left.Insert(i, 1)
+		right.Insert(1000+i, 1)
+	}
+	right.Aggregate(math.MinInt, math.MaxInt)
+	tree, err := Join(left, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := tree.Aggregate(math.MinInt, math.MaxInt); sum != 200 {
+		t.Fatalf("sum %d after joining a Max tree", sum)
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSplitJoinKeyState(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var evicted []KeyType
+	tree := New(3, 3, WithClock(func() time.Time { return now }), WithLimit(40, 0, EvictLRU),
+		WithEvictCallback(func(key KeyType, value DataType) { evicted = append(evicted, key) }))
+	for k := 0; k < 40; k++ {
+		tree.InsertWithTTL(k, k, time.Duration(k+1)*time.Minute)
+	}
+	/* 0 becomes the most recently used key */
+	tree.Search(0)
+	left, right := tree.SplitAt(20)
+	if _, ok := left.TTL(30); ok {
+		t.Fatal("moved key keeps its deadline on the left")
+	}
+	if d, ok := right.TTL(30); !ok || d != 31*time.Minute {
+		t.Fatalf("right TTL(30) = %v, %v", d, ok)
+	}
+	if len(left.limit.elems) != 20 || len(right.limit.elems) != 20 {
+		t.Fatalf("use lists of %d and %d keys", len(left.limit.elems), len(right.limit.elems))
+	}
+	/* each half evicts its own least recently used key */
+	for k := 100; k < 121; k++ {
+		left.Insert(k, k)
+		right.Insert(k+100, k)
+	}
+	if len(evicted) != 2 || evicted[0] != 1 || evicted[1] != 20 {
+		t.Fatalf("evicted %v", evicted)
+	}
+	for _, tr := range []*BPlusTree{left, right} {
+		if err := tr.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	now = now.Add(90 * time.Second)
+	for k := 100; k < 121; k++ {
+		left.Delete(k)
+		right.Delete(k + 100)
+	}
+	joined, err := Join(left, right)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, ok := joined.TTL(30); !ok || d != 29*time.Minute+30*time.Second {
+		t.Fatalf("joined TTL(30) = %v, %v", d, ok)
+	}
+	if joined.ExpireBefore(now) != 1 || joined.Len() != 37 || len(joined.limit.elems) != 37 {
+		t.Fatalf("%d keys and %d in the use list", joined.Len(), len(joined.limit.elems))
+	}
+}
@@ -0,0 +1,131 @@
+package bplustree
+
+import (
+	"unsafe"
+)
+
+// FillBuckets is the number of buckets in a Stats fill-factor histogram.
+// Bucket i counts nodes whose occupancy lies in [i/FillBuckets,
+// (i+1)/FillBuckets), with full nodes counted in the last bucket.
+const FillBuckets = 10
+
+type treeCounters struct {
+	leafSplits   uint64
+	innerSplits  uint64
+	leafMerges   uint64
+	innerMerges  uint64
+	leafBorrows  uint64
+	innerBorrows uint64
+	evictions    uint64
+}
+
+// Stats describes the shape and memory use of a tree.
+type Stats struct {
+	/** number of key-value pairs */
+	Entries int
+	/** number of node levels, 0 for an empty tree */
+	Height int
+	/** number of nodes per level, the root level first */
+	NodesPerLevel []int
+	LeafNodes     int
+	InnerNodes    int
+
+	/** occupancy histograms, relative to the leaf capacity and the order */
+	LeafFill  [FillBuckets]int
+	InnerFill [FillBuckets]int
+	/** average and minimum occupancy, in [0, 1] */
+	AvgLeafFill  float64
+	MinLeafFill  float64
+	AvgInnerFill float64
+	MinInnerFill float64
+
+	/** estimated bytes held by the tree, including unused array slots */
+	Bytes int64
+	/** part of Bytes spent on the unused tail of the kvs and key arrays */
+	UnusedBytes int64
+
+	/** cumulative structural changes since creation */
+	LeafSplits   uint64
+	InnerSplits  uint64
+	LeafMerges   uint64
+	InnerMerges  uint64
+	LeafBorrows  uint64
+	InnerBorrows uint64
+	/** pairs removed by the size limit */
+	Evictions uint64
+}
+
+func fillBucket(fill float64) int {
+	b := int(fill * FillBuckets)
+	if b >= FillBuckets {
+		b = FillBuckets - 1
+	}
+	return b
+}
+
+// Stats walks the tree and reports its shape, occupancy and memory use.
+func (tree *BPlusTree) Stats() Stats {
+	st := Stats{
+		Entries:      tree.count,
+		Bytes:        int64(unsafe.Sizeof(*tree)),
+		LeafSplits:   tree.counters.leafSplits,
+		InnerSplits:  tree.counters.innerSplits,
+		LeafMerges:   tree.counters.leafMerges,
+		InnerMerges:  tree.counters.innerMerges,
+		LeafBorrows:  tree.counters.leafBorrows,
+		InnerBorrows: tree.counters.innerBorrows,
+		Evictions:    tree.counters.evictions,
+	}
+	if tree.root == nil {
+		return st
+	}
+
+	var leaf bplusLeaf
+	var nonLeaf bplusNonLeaf
+	kvSize := int64(unsafe.Sizeof(leaf.kvs[0]))
+	slotSize := int64(unsafe.Sizeof(nonLeaf.key[0]) + unsafe.Sizeof(nonLeaf.subPtr[0]))
+
+	st.Height = tree.level + 1
+	st.NodesPerLevel = make([]int, st.Height)
+	st.MinLeafFill, st.MinInnerFill = 1, 1
+	var leafSum, innerSum float64
+
+	var walk func(n node, depth int)
+	walk = func(n node, depth int) {
+		st.NodesPerLevel[depth]++
+		if leaf, ok := n.(*bplusLeaf); ok {
+			fill := float64(leaf.entries) / float64(tree.entries)
+			st.LeafNodes++
+			st.LeafFill[fillBucket(fill)]++
+			leafSum += fill
+			if fill < st.MinLeafFill {
+				st.MinLeafFill = fill
+			}
+			st.Bytes += int64(unsafe.Sizeof(*leaf))
+			st.UnusedBytes += int64(MaxEntries-leaf.entries) * kvSize
+			return
+		}
+		nl := n.(*bplusNonLeaf)
+		fill := float64(nl.children) / float64(tree.order)
+		st.InnerNodes++
+		st.InnerFill[fillBucket(fill)]++
+		innerSum += fill
+		if fill < st.MinInnerFill {
+			st.MinInnerFill = fill
+		}
+		st.Bytes += int64(unsafe.Sizeof(*nl))
+		st.UnusedBytes += int64(MaxOrder-nl.children) * slotSize
+		for i := 0; i < nl.children; i++ {
+			walk(nl.subPtr[i], depth+1)
+		}
+	}
+	walk(tree.root, 0)
+
+	st.AvgLeafFill = leafSum / float64(st.LeafNodes)
+	if st.InnerNodes > 0 {
+		st.AvgInnerFill = innerSum / float64(st.InnerNodes)
+	} else {
+		st.MinInnerFill = 0
+	}
+	return st
+}
@@ -0,0 +1,43 @@
+package bplustree
+
+import (
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	tree := New(4, 4)
+	if st := tree.Stats(); st.Height != 0 || st.LeafNodes != 0 || st.Bytes == 0 {
+		t.Fatalf("empty tree stats: %+v", st)
+	}
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+	for i := 0; i < 100; i += 2 {
+		tree.Delete(i)
+	}
+
+	st := tree.Stats()
+	if st.Entries != 50 || st.Height != tree.level+1 || st.NodesPerLevel[0] != 1 {
+		t.Fatalf("shape: %+v", st)
+	}
+	if st.NodesPerLevel[st.Height-1] != st.LeafNodes {
+		t.Fatalf("leaf level holds %d nodes, counted %d leaves", st.NodesPerLevel[st.Height-1], st.LeafNodes)
+	}
+	var leaves, inner int
+	for i := 0; i < FillBuckets; i++ {
+		leaves += st.LeafFill[i]
+		inner += st.InnerFill[i]
+	}
+	if leaves != st.LeafNodes || inner != st.InnerNodes {
+		t.Fatalf("histograms: %+v", st)
+	}
+	if st.MinLeafFill < 0.5 || st.MinLeafFill > st.AvgLeafFill || st.AvgLeafFill > 1 {
+		t.Fatalf("leaf fill: min %v avg %v", st.MinLeafFill, st.AvgLeafFill)
+	}
+	if st.UnusedBytes <= 0 || st.UnusedBytes >= st.Bytes {
+		t.Fatalf("bytes: %d unused of %d", st.UnusedBytes, st.Bytes)
+	}
+	if st.LeafSplits == 0 || st.InnerSplits == 0 || st.LeafMerges+st.LeafBorrows == 0 {
+		t.Fatalf("counters: %+v", st)
+	}
+}
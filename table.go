@@ -0,0 +1,137 @@
+package bplustree
+
+// Index describes a secondary index of an IndexedTable.
+type Index struct {
+	/** name used by Lookup */
+	Name string
+	/** extracts the index key of a row */
+	Key func(id KeyType, row DataType) KeyType
+	/** at most one row per index key */
+	Unique bool
+}
+
+type tableIndex struct {
+	Index
+	/** index key -> row id, a multimap unless the index is unique */
+	tree *BPlusTree
+}
+
+// IndexedTable keeps a primary tree keyed by row id and any number of
+// secondary index trees in sync. Every mutation either updates all the
+// trees or, when a unique index rejects it, none of them.
+type IndexedTable struct {
+	primary *BPlusTree
+	indexes []*tableIndex
+}
+
+// NewIndexedTable returns an empty table whose trees are created with the
+// given non-leaf order and leaf capacity.
+func NewIndexedTable(order int, entries int, indexes ...Index) *IndexedTable {
+	t := &IndexedTable{primary: New(order, entries)}
+	for _, idx := range indexes {
+		assert(idx.Key != nil)
+		ti := &tableIndex{Index: idx}
+		if idx.Unique {
+			ti.tree = New(order, entries)
+		} else {
+			ti.tree = New(order, entries, Multi())
+		}
+		t.indexes = append(t.indexes, ti)
+	}
+	return t
+}
+
+func (ti *tableIndex) insert(key KeyType, id KeyType) int {
+	return ti.tree.Insert(key, id)
+}
+
+func (ti *tableIndex) remove(key KeyType, id KeyType) {
+	ret := ti.tree.DeleteOne(key, id)
+	assert(ret == 0)
+}
+
+// Len returns the number of rows.
+func (t *IndexedTable) Len() int {
+	return t.primary.Len()
+}
+
+// Get returns the row stored under id.
+func (t *IndexedTable) Get(id KeyType) (DataType, bool) {
+	return t.primary.Search(id)
+}
+
+// Lookup returns the ids of the rows whose key in the named index equals
+// key, in index order. It returns nil for an unknown index.
+func (t *IndexedTable) Lookup(index string, key KeyType) []KeyType {
+	for _, ti := range t.indexes {
+		if ti.Name == index {
+			return ti.tree.SearchAll(key)
+		}
+	}
+	return nil
+}
+
+// Insert adds a row. It returns -1 and changes nothing if id already exists
+// or a unique index already holds the row's key.
+func (t *IndexedTable) Insert(id KeyType, row DataType) int {
+	if t.primary.Insert(id, row) != 0 {
+		return -1
+	}
+	for i, ti := range t.indexes {
+		if ti.insert(ti.Key(id, row), id) != 0 {
+			/* roll back the indexes already updated */
+			for _, done := range t.indexes[:i] {
+				done.remove(done.Key(id, row), id)
+			}
+			t.primary.Delete(id)
+			return -1
+		}
+	}
+	return 0
+}
+
+// Update replaces the row stored under id. It returns -1 and changes
+// nothing if id does not exist or a unique index already holds the new
+// row's key.
+func (t *IndexedTable) Update(id KeyType, row DataType) int {
+	old, ok := t.primary.Search(id)
+	if !ok {
+		return -1
+	}
+	/* add the new index keys first so a conflict can be undone */
+	for i, ti := range t.indexes {
+		oldKey, newKey := ti.Key(id, old), ti.Key(id, row)
+		if oldKey == newKey {
+			continue
+		}
+		if ti.insert(newKey, id) != 0 {
+			for _, done := range t.indexes[:i] {
+				if k := done.Key(id, row); k != done.Key(id, old) {
+					done.remove(k, id)
+				}
+			}
+			return -1
+		}
+	}
+	for _, ti := range t.indexes {
+		if oldKey := ti.Key(id, old); oldKey != ti.Key(id, row) {
+			ti.remove(oldKey, id)
+		}
+	}
+	t.primary.Update(id, row)
+	return 0
+}
+
+// Delete removes the row stored under id from the primary tree and every
+// index. It returns -1 if id does not exist.
+func (t *IndexedTable) Delete(id KeyType) int {
+	row, ok := t.primary.Search(id)
+	if !ok {
+		return -1
+	}
+	for _, ti := range t.indexes {
+		ti.remove(ti.Key(id, row), id)
+	}
+	t.primary.Delete(id)
+	return 0
+}
@@ -0,0 +1,51 @@
+package bplustree
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIndexedTable(t *testing.T) {
+	/* rows encode email*100 + status */
+	table := NewIndexedTable(3, 3,
+		Index{Name: "email", Unique: true, Key: func(id KeyType, row DataType) KeyType { return row / 100 }},
+		Index{Name: "status", Key: func(id KeyType, row DataType) KeyType { return row % 100 }},
+	)
+	for id := 1; id <= 20; id++ {
+		if table.Insert(id, id*100+id%3) != 0 {
+			t.Fatalf("Insert(%d) failed", id)
+		}
+	}
+	if table.Insert(21, 500+1) != -1 {
+		t.Fatalf("duplicate email accepted")
+	}
+	if table.Insert(5, 2100) != -1 {
+		t.Fatalf("duplicate id accepted")
+	}
+	if _, ok := table.Get(21); ok || table.Len() != 20 || len(table.Lookup("status", 1)) != 7 {
+		t.Fatalf("failed insert left a trace")
+	}
+
+	if table.Update(4, 700) != -1 {
+		t.Fatalf("update to a taken email accepted")
+	}
+	if got := table.Lookup("email", 4); fmt.Sprint(got) != "[4]" {
+		t.Fatalf("Lookup(email, 4) = %v after failed update", got)
+	}
+	if table.Update(4, 4000+2) != 0 {
+		t.Fatalf("Update(4) failed")
+	}
+	if got := table.Lookup("email", 40); fmt.Sprint(got) != "[4]" || table.Lookup("email", 4) != nil {
+		t.Fatalf("Update(4) did not move the email index")
+	}
+	if got := table.Lookup("status", 2); fmt.Sprint(got) != "[2 5 8 11 14 17 20 4]" {
+		t.Fatalf("Lookup(status, 2) = %v", got)
+	}
+
+	if table.Delete(4) != 0 || table.Delete(4) != -1 {
+		t.Fatalf("Delete(4)")
+	}
+	if table.Lookup("email", 40) != nil || len(table.Lookup("status", 2)) != 7 || table.Len() != 19 {
+		t.Fatalf("Delete(4) left index entries behind")
+	}
+}
@@ -28,7 +28,7 @@ func getPutTest(tree *bplustree.BPlusTree) {
 	tree.Insert(44, 44)
 	tree.Insert(68, 68)
 	tree.Insert(74, 74)
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	tree.Insert(10, 10)
 	tree.Insert(15, 15)
@@ -46,7 +46,7 @@ func getPutTest(tree *bplustree.BPlusTree) {
 	tree.Insert(78, 78)
 	tree.Insert(81, 81)
 	tree.Insert(84, 84)
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	var nums = []int{24, 72, 1, 39, 53, 63, 90, 88, 15, 10, 44, 68}
 	for _, n := range nums {
@@ -69,7 +69,7 @@ func getPutTest(tree *bplustree.BPlusTree) {
 	for i := 1; i <= 100; i++ {
 		tree.Delete(i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	/* Not found */
 	_, found = tree.Search(100)
@@ -90,52 +90,52 @@ func insertDeleteTest(tree *bplustree.BPlusTree) {
 	for i = 1; i <= max_key; i++ {
 		tree.Insert(i, i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	fmt.Fprintf(os.Stderr, "\n-- Delete 1 to %d, dump:\n", max_key)
 	for i = 1; i <= max_key; i++ {
 		tree.Delete(i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	/* Ordered insertion and reversed deletion */
 	fmt.Fprintf(os.Stderr, "\n-- Insert 1 to %d, dump:\n", max_key)
 	for i = 1; i <= max_key; i++ {
 		tree.Insert(i, i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	fmt.Fprintf(os.Stderr, "\n-- Delete %d to 1, dump:\n", max_key)
 	for i--; i > 0; i-- {
 		tree.Delete(i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	/* Reversed insertion and ordered deletion */
 	fmt.Fprintf(os.Stderr, "\n-- Insert %d to 1, dump:\n", max_key)
 	for i = max_key; i > 0; i-- {
 		tree.Insert(i, i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	fmt.Fprintf(os.Stderr, "\n-- Delete 1 to %d, dump:\n", max_key)
 	for i = 1; i <= max_key; i++ {
 		tree.Delete(i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	/* Reversed insertion and reversed deletion */
 	fmt.Fprintf(os.Stderr, "\n-- Insert %d to 1, dump:\n", max_key)
 	for i = max_key; i > 0; i-- {
 		tree.Insert(i, i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 
 	fmt.Fprintf(os.Stderr, "\n-- Delete %d to 1, dump:\n", max_key)
 	for i = max_key; i > 0; i-- {
 		tree.Delete(i)
 	}
-	bplustree.Dump(tree)
+	_ = tree.DumpTo(os.Stderr, bplustree.DumpText)
 }
 
 func normalTest() {
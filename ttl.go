@@ -0,0 +1,144 @@
+package bplustree
+
+import (
+	"time"
+)
+
+// ttlIndex holds the deadlines of the keys inserted with a TTL.
+type ttlIndex struct {
+	/** deadline of each key, in Unix nanoseconds */
+	deadlines map[KeyType]int64
+	/** the keys as values under their deadlines, soonest first */
+	byDeadline *BPlusTree
+}
+
+// WithClock makes the tree read the current time from now instead of
+// time.Now when deciding whether a key has expired.
+func WithClock(now func() time.Time) Option {
+	return func(tree *BPlusTree) {
+		tree.clock = now
+	}
+}
+
+func (tree *BPlusTree) now() time.Time {
+	if tree.clock != nil {
+		return tree.clock()
+	}
+	return time.Now()
+}
+
+// InsertWithTTL inserts key like Insert and makes it expire ttl from now.
+// An expired key is hidden at once from Search, Update, SearchAll, Count,
+// GetRange, Aggregate and iterators, and removed by the next of these
+// point operations or Insert on it, by Aggregate or by ExpireBefore; until
+// then it still counts in Len. Deleting a key drops its deadline. The tree
+// must not be a multimap.
+func (tree *BPlusTree) InsertWithTTL(key KeyType, data DataType, ttl time.Duration) int {
+	assert(!tree.multi)
+	tree.expire(key)
+	if tree.insert(key, data) != 0 {
+		return -1
+	}
+	/* the deadline must be in place before eviction may remove the key */
+	tree.setDeadline(key, tree.now().Add(ttl).UnixNano())
+	tree.evict()
+	return 0
+}
+
+// setDeadline makes key expire at deadline, in Unix nanoseconds.
+func (tree *BPlusTree) setDeadline(key KeyType, deadline int64) {
+	if tree.ttl == nil {
+		tree.ttl = &ttlIndex{
+			deadlines:  make(map[KeyType]int64),
+			byDeadline: New(tree.order, tree.entries, Multi()),
+		}
+	}
+	tree.ttl.deadlines[key] = deadline
+	tree.ttl.byDeadline.Insert(KeyType(deadline), key)
+}
+
+// deadline returns the deadline of key in Unix nanoseconds, 0 if it has
+// none.
+func (tree *BPlusTree) deadline(key KeyType) int64 {
+	if tree.ttl == nil {
+		return 0
+	}
+	return tree.ttl.deadlines[key]
+}
+
+// TTL returns the time left before key expires, and false if key has no
+// deadline.
+func (tree *BPlusTree) TTL(key KeyType) (time.Duration, bool) {
+	if tree.ttl == nil {
+		return 0, false
+	}
+	deadline, ok := tree.ttl.deadlines[key]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(deadline - tree.now().UnixNano()), true
+}
+
+// expired reports whether key has a deadline that has passed.
+func (tree *BPlusTree) expired(key KeyType) bool {
+	if tree.ttl == nil {
+		return false
+	}
+	deadline, ok := tree.ttl.deadlines[key]
+	return ok && deadline <= tree.now().UnixNano()
+}
+
+// hasExpired reports whether some key has a deadline that has passed.
+func (tree *BPlusTree) hasExpired() bool {
+	if tree.ttl == nil {
+		return false
+	}
+	it := tree.ttl.byDeadline.First()
+	return it.Valid() && it.Key() <= KeyType(tree.now().UnixNano())
+}
+
+// expire removes key if it has expired and reports whether it has. An open
+// transaction keeps the expired pair in place, hidden, so that every
+// removal it has to undo goes through its log.
+func (tree *BPlusTree) expire(key KeyType) bool {
+	if !tree.expired(key) {
+		return false
+	}
+	if tree.txn == nil {
+		tree.Delete(key)
+	}
+	return true
+}
+
+// forget drops the deadline of a removed key.
+func (tree *BPlusTree) forget(key KeyType) {
+	deadline, ok := tree.ttl.deadlines[key]
+	if !ok {
+		return
+	}
+	delete(tree.ttl.deadlines, key)
+	tree.ttl.byDeadline.DeleteOne(KeyType(deadline), key)
+}
+
+// ExpireBefore removes every key whose deadline is not after now and
+// returns how many were removed. The expiry index yields the keys without
+// scanning the leaves, and they are removed in one DeleteMany batch. The
+// tree is not safe for concurrent use, so a background sweeper must call it
+// under the same lock as every other operation. It removes nothing while a
+// transaction is open.
+func (tree *BPlusTree) ExpireBefore(now time.Time) int {
+	if tree.ttl == nil || tree.txn != nil {
+		return 0
+	}
+	var keys []KeyType
+	for it := tree.ttl.byDeadline.First(); it.Valid() && it.Key() <= KeyType(now.UnixNano()); it.Next() {
+		keys = append(keys, it.Value())
+	}
+	var n int
+	for _, ret := range tree.DeleteMany(keys) {
+		if ret == 0 {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,153 @@
+package bplustree
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTL(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tree := New(3, 3, WithClock(func() time.Time { return now }))
+	for k := 0; k < 100; k++ {
+		switch {
+		case k%10 == 0:
+			tree.Insert(k, k)
+		case k%2 == 0:
+			tree.InsertWithTTL(k, k, time.Minute)
+		default:
+			tree.InsertWithTTL(k, k, time.Duration(k)*time.Second)
+		}
+	}
+	if d, ok := tree.TTL(3); !ok || d != 3*time.Second {
+		t.Fatalf("TTL(3) = %v, %v", d, ok)
+	}
+	if _, ok := tree.TTL(10); ok {
+		t.Fatal("TTL(10) has a deadline")
+	}
+	tree.Delete(5)
+	if _, ok := tree.TTL(5); ok {
+		t.Fatal("deleted key keeps its deadline")
+	}
+
+	now = now.Add(30 * time.Second)
+	/* expired keys are hidden at once */
+	if _, ok := tree.Search(7); ok {
+		t.Fatal("expired key 7 found")
+	}
+	if _, ok := tree.Search(31); !ok {
+		t.Fatal("live key 31 not found")
+	}
+	var n int
+	for k := range tree.Ascend(0, 99) {
+		if k%2 == 1 && k <= 30 {
+			t.Fatalf("iteration yields expired key %d", k)
+		}
+		n++
+	}
+	if it := tree.Last(); !it.Valid() || it.Key() != 99 {
+		t.Fatal("Last is not 99")
+	}
+	if it := tree.Seek(1); !it.Valid() || it.Key() != 2 {
+		t.Fatal("Seek(1) does not skip to 2")
+	}
+	/* 7 went with the Search, the other odd keys up to 29 are swept */
+	if got := tree.ExpireBefore(now); got != 13 {
+		t.Fatalf("ExpireBefore removed %d keys, want 13", got)
+	}
+	if tree.Len() != n {
+		t.Fatalf("Len = %d after sweep, want %d", tree.Len(), n)
+	}
+	if tree.InsertWithTTL(31, 0, time.Second) != -1 {
+		t.Fatal("InsertWithTTL over a live key succeeded")
+	}
+
+	now = now.Add(time.Hour)
+	if tree.InsertWithTTL(31, -31, time.Second) != 0 {
+		t.Fatal("InsertWithTTL over an expired key failed")
+	}
+	if got := tree.ExpireBefore(now); got != n-11 {
+		t.Fatalf("ExpireBefore removed %d keys, want %d", got, n-11)
+	}
+	if tree.Len() != 11 {
+		t.Fatalf("%d keys left, want 10 without deadline and 31", tree.Len())
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTTLOperations(t *testing.T) {
+	now := time.Unix(1000, 0)
+	clock := WithClock(func() time.Time { return now })
+	tree := New(3, 3, clock, WithAggregate(Sum))
+	for k := 0; k < 20; k++ {
+		if k%2 == 0 {
+			tree.InsertWithTTL(k, k, time.Minute)
+		} else {
+			tree.Insert(k, k)
+		}
+	}
+	now = now.Add(2 * time.Minute)
+
+	if ret := tree.Insert(2, 200); ret != 0 {
+		t.Fatal("Insert over an expired key failed")
+	}
+	if v, ok := tree.Search(2); !ok || v != 200 {
+		t.Fatalf("Search(2) = %d, %v after Insert", v, ok)
+	}
+	if _, ok := tree.TTL(2); ok {
+		t.Fatal("reinserted key keeps its deadline")
+	}
+	if ret := tree.Update(4, 400); ret != -1 {
+		t.Fatal("Update of an expired key succeeded")
+	}
+	if got := tree.SearchAll(6); got != nil {
+		t.Fatalf("SearchAll(6) = %v", got)
+	}
+	if n := tree.Count(8); n != 0 {
+		t.Fatalf("Count(8) = %d", n)
+	}
+	if v, ok := tree.GetRange(9, 10); !ok || v != 9 {
+		t.Fatalf("GetRange(9, 10) = %d, %v", v, ok)
+	}
+	if _, ok := tree.GetRange(10, 10); ok {
+		t.Fatal("GetRange found expired key 10")
+	}
+	/* 200 and the odd keys */
+	if sum := tree.Aggregate(0, 19); sum != 300 {
+		t.Fatalf("Aggregate = %d", sum)
+	}
+	if tree.Len() != 11 {
+		t.Fatalf("%d keys left after Aggregate", tree.Len())
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	/* an open transaction folds the live pairs without removing any */
+	tree.InsertWithTTL(20, 20, time.Minute)
+	now = now.Add(2 * time.Minute)
+	txn := tree.Begin()
+	if sum := tree.Aggregate(0, 20); sum != 300 || tree.Len() != 12 {
+		t.Fatalf("Aggregate = %d with %d keys in a transaction", sum, tree.Len())
+	}
+	txn.Commit()
+}
+
+func TestTTLEvicted(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tree := New(3, 3, WithClock(func() time.Time { return now }), WithLimit(2, 0, EvictSmallest))
+	tree.Insert(5, 5)
+	tree.Insert(6, 6)
+	/* 1 is the smallest key and evicted at once */
+	tree.InsertWithTTL(1, 1, time.Second)
+	if _, ok := tree.TTL(1); ok {
+		t.Fatal("evicted key keeps its deadline")
+	}
+	tree.Delete(5)
+	tree.Insert(1, 100)
+	now = now.Add(2 * time.Second)
+	if v, ok := tree.Search(1); !ok || v != 100 {
+		t.Fatalf("Search(1) = %d, %v", v, ok)
+	}
+}
@@ -0,0 +1,146 @@
+package bplustree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Tuple is a composite key such as (tenant, timestamp, id). Its encoding
+// sorts component-wise and the encoding of a tuple prefix is a byte prefix
+// of the encoding of every longer tuple, so tuples are stored in a
+// BytesTree and partial keys are looked up with prefix scans. The converse
+// does not hold: ("a\x00b") encodes to bytes starting with those of ("a"),
+// as a zero byte is escaped as 0x00 0xff, so the scans here stop short of
+// the 0xff that would continue the last component of the prefix.
+//
+// Components may be int, int64, string or []byte. Integers decode as int64.
+// Components of different types order by type: []byte < string < integer.
+type Tuple []any
+
+const (
+	tupleBytes  = 0x01
+	tupleString = 0x02
+	tupleInt    = 0x03
+)
+
+var errTuple = errors.New("bplustree: invalid tuple encoding")
+
+// Encode returns the order-preserving encoding of the tuple.
+func (t Tuple) Encode() []byte {
+	return t.AppendEncode(nil)
+}
+
+// AppendEncode appends the encoding of the tuple to dst.
+func (t Tuple) AppendEncode(dst []byte) []byte {
+	for _, c := range t {
+		switch v := c.(type) {
+		case int:
+			dst = appendTupleInt(dst, int64(v))
+		case int64:
+			dst = appendTupleInt(dst, v)
+		case string:
+			dst = appendTupleBytes(append(dst, tupleString), []byte(v))
+		case []byte:
+			dst = appendTupleBytes(append(dst, tupleBytes), v)
+		default:
+			panic(fmt.Sprintf("bplustree: unsupported tuple component %T", c))
+		}
+	}
+	return dst
+}
+
+func appendTupleInt(dst []byte, v int64) []byte {
+	/* flip the sign bit so negative numbers sort first */
+	return binary.BigEndian.AppendUint64(append(dst, tupleInt), uint64(v)^(1<<63))
+}
+
+func appendTupleBytes(dst []byte, b []byte) []byte {
+	/* escape 0x00 as 0x00 0xff and terminate with 0x00 */
+	for _, c := range b {
+		dst = append(dst, c)
+		if c == 0x00 {
+			dst = append(dst, 0xff)
+		}
+	}
+	return append(dst, 0x00)
+}
+
+// DecodeTuple decodes a key produced by Tuple.Encode.
+func DecodeTuple(b []byte) (Tuple, error) {
+	var t Tuple
+	for len(b) > 0 {
+		tag := b[0]
+		b = b[1:]
+		switch tag {
+		case tupleInt:
+			if len(b) < 8 {
+				return nil, errTuple
+			}
+			t = append(t, int64(binary.BigEndian.Uint64(b)^(1<<63)))
+			b = b[8:]
+		case tupleString, tupleBytes:
+			var v []byte
+			for {
+				if len(b) == 0 {
+					return nil, errTuple
+				}
+				c := b[0]
+				b = b[1:]
+				if c != 0x00 {
+					v = append(v, c)
+				} else if len(b) > 0 && b[0] == 0xff {
+					v = append(v, 0x00)
+					b = b[1:]
+				} else {
+					break
+				}
+			}
+			if tag == tupleString {
+				t = append(t, string(v))
+			} else {
+				t = append(t, v)
+			}
+		default:
+			return nil, errTuple
+		}
+	}
+	return t, nil
+}
+
+func (tree *BytesTree) scanTuples(start, end []byte, fn func(key Tuple, value DataType) bool) {
+	tree.scan(start, end, func(key []byte, value DataType) bool {
+		t, err := DecodeTuple(key)
+		if err != nil {
+			/* not a tuple key */
+			return true
+		}
+		return fn(t, value)
+	})
+}
+
+// ScanTuplePrefix calls fn in ascending order for every tuple key whose
+// leading components equal prefix, until fn returns false. Keys that are not
+// valid tuple encodings are skipped.
+func (tree *BytesTree) ScanTuplePrefix(prefix Tuple, fn func(key Tuple, value DataType) bool) {
+	p := prefix.Encode()
+	tree.scanTuples(p, tupleEnd(p), fn)
+}
+
+// tupleEnd returns the smallest key greater than every encoding of a tuple
+// starting with the tuple encoded as p. A longer tuple continues p with a
+// type tag, while a 0xff continues its last component.
+func tupleEnd(p []byte) []byte {
+	return append(p[:len(p):len(p)], 0xff)
+}
+
+// ScanTupleRange calls fn in ascending order for every tuple key whose
+// leading components equal prefix and whose next component lies in
+// [lo, hi), until fn returns false. Keys that are not valid tuple encodings
+// are skipped.
+func (tree *BytesTree) ScanTupleRange(prefix Tuple, lo, hi any, fn func(key Tuple, value DataType) bool) {
+	p := prefix.Encode()
+	start := Tuple{lo}.AppendEncode(p[:len(p):len(p)])
+	end := Tuple{hi}.AppendEncode(p[:len(p):len(p)])
+	tree.scanTuples(start, end, fn)
+}
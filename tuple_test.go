@@ -0,0 +1,130 @@
+package bplustree
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestTupleOrder(t *testing.T) {
+	tuples := []Tuple{
+		{"a"}, {"a", -5}, {"a", 0}, {"a", 7, "x"}, {"a\x00"}, {"a\x00b"}, {"ab"},
+		{"b", -1 << 40}, {"b", int64(1) << 40}, {[]byte{0xff}}, {"", 1},
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		tuples = append(tuples, Tuple{fmt.Sprint(r.Intn(5)), r.Intn(200) - 100, r.Intn(3)})
+	}
+	less := func(a, b Tuple) bool {
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if c := compareComponent(a[i], b[i]); c != 0 {
+				return c < 0
+			}
+		}
+		return len(a) < len(b)
+	}
+	for _, a := range tuples {
+		d, err := DecodeTuple(a.Encode())
+		if err != nil || fmt.Sprint(d) != fmt.Sprint(a) {
+			t.Fatalf("DecodeTuple(%v) = %v, %v", a, d, err)
+		}
+		for _, b := range tuples {
+			if less(a, b) != (bytes.Compare(a.Encode(), b.Encode()) < 0) {
+				t.Fatalf("%v < %v disagrees with encoding", a, b)
+			}
+		}
+	}
+}
+
+func compareComponent(a, b any) int {
+	rank := func(v any) int {
+		switch v.(type) {
+		case []byte:
+			return 0
+		case string:
+			return 1
+		}
+		return 2
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	switch v := a.(type) {
+	case []byte:
+		return bytes.Compare(v, b.([]byte))
+	case string:
+		return bytes.Compare([]byte(v), []byte(b.(string)))
+	}
+	x, y := toInt64(a), toInt64(b)
+	if x < y {
+		return -1
+	} else if x > y {
+		return 1
+	}
+	return 0
+}
+
+func toInt64(v any) int64 {
+	if i, ok := v.(int); ok {
+		return int64(i)
+	}
+	return v.(int64)
+}
+
+func TestScanTuple(t *testing.T) {
+	tree := NewBytes(128)
+	for tenant := 0; tenant < 5; tenant++ {
+		for ts := 100; ts < 120; ts++ {
+			for id := 0; id < 3; id++ {
+				tree.Insert(Tuple{fmt.Sprintf("tenant%d", tenant), ts, id}.Encode(), tenant*1000+ts*10+id)
+			}
+		}
+	}
+	tree.Insert([]byte("not a tuple"), 0)
+	/* the encoding starts with that of ("tenant4") */
+	tree.Insert(Tuple{"tenant4\x00x", 100, 0}.Encode(), -1)
+
+	var got []DataType
+	tree.ScanTuplePrefix(Tuple{"tenant4"}, func(key Tuple, value DataType) bool {
+		if key[0] != "tenant4" {
+			t.Fatalf("key %q outside prefix", key)
+		}
+		got = append(got, value)
+		return true
+	})
+	if len(got) != 60 || !sort.IntsAreSorted(got) || got[0] != 5000 {
+		t.Fatalf("ScanTuplePrefix(tenant4) = %v", got)
+	}
+
+	got = got[:0]
+	tree.ScanTupleRange(Tuple{"tenant2"}, 105, 107, func(key Tuple, value DataType) bool {
+		if key[0] != "tenant2" {
+			t.Fatalf("key %v outside prefix", key)
+		}
+		got = append(got, value)
+		return true
+	})
+	if fmt.Sprint(got) != "[3050 3051 3052 3060 3061 3062]" {
+		t.Fatalf("ScanTupleRange(tenant2, 105, 107) = %v", got)
+	}
+
+	got = got[:0]
+	tree.ScanTuplePrefix(Tuple{"tenant1", 110}, func(key Tuple, value DataType) bool {
+		got = append(got, value)
+		return len(got) < 2
+	})
+	if fmt.Sprint(got) != "[2100 2101]" {
+		t.Fatalf("ScanTuplePrefix(tenant1, 110) = %v", got)
+	}
+
+	got = got[:0]
+	tree.ScanTupleRange(Tuple{}, "tenant4", "tenant4\x00y", func(key Tuple, value DataType) bool {
+		got = append(got, value)
+		return true
+	})
+	if len(got) != 61 || got[60] != -1 {
+		t.Fatalf("ScanTupleRange(tenant4, tenant4\\x00y) = %v", got)
+	}
+}
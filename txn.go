@@ -0,0 +1,164 @@
+package bplustree
+
+import (
+	"errors"
+	"iter"
+)
+
+// ErrTxnDone is returned when a transaction is committed or rolled back
+// more than once.
+var ErrTxnDone = errors.New("bplustree: transaction already committed or rolled back")
+
+type undoOp int
+
+const (
+	/** the key was inserted, undone by removing it */
+	undoInsert undoOp = iota
+	/** the key was deleted, undone by inserting it back */
+	undoDelete
+	/** the value was replaced, undone by restoring it */
+	undoUpdate
+)
+
+type undoRecord struct {
+	op    undoOp
+	key   KeyType
+	value DataType
+	/** deadline of a deleted key, 0 if it had none */
+	deadline int64
+}
+
+// Txn groups inserts and deletes that are applied all together or not at
+// all. Writes go straight to the tree and are recorded in an undo log that
+// Rollback replays backwards. A transaction gives atomicity, not isolation:
+// readers of the tree see its writes before Commit. Only one transaction
+// may be open on a tree at a time, and the tree must not be modified
+// outside of it while it is open. Keys that expire meanwhile stay hidden
+// in place until it closes, and Rollback restores the deadlines of the
+// keys it brings back.
+type Txn struct {
+	tree *BPlusTree
+	undo []undoRecord
+	done bool
+}
+
+// Begin opens a transaction on the tree.
+func (tree *BPlusTree) Begin() *Txn {
+	if tree.txn != nil {
+		panic("bplustree: a transaction is already open")
+	}
+	txn := &Txn{tree: tree}
+	tree.txn = txn
+	return txn
+}
+
+func (txn *Txn) check() {
+	if txn.done {
+		panic(ErrTxnDone)
+	}
+}
+
+// Insert behaves like BPlusTree.Insert.
+func (txn *Txn) Insert(key KeyType, data DataType) int {
+	txn.check()
+	tree := txn.tree
+	if tree.expired(key) {
+		/* lazy expiry is held back, remove the expired pair as a logged delete */
+		leaf, i := tree.seekFirst(key)
+		txn.undo = append(txn.undo, undoRecord{undoDelete, key, leaf.kvs[i].value, tree.deadline(key)})
+		tree.leafRemove(leaf, i)
+	}
+	ret := tree.Insert(key, data)
+	if ret == 0 {
+		txn.undo = append(txn.undo, undoRecord{op: undoInsert, key: key, value: data})
+	}
+	return ret
+}
+
+// Delete behaves like BPlusTree.Delete.
+func (txn *Txn) Delete(key KeyType) int {
+	txn.check()
+	/* Search finds the pair Delete removes, the first of the equal keys */
+	value, ok := txn.tree.Search(key)
+	if !ok {
+		return -1
+	}
+	deadline := txn.tree.deadline(key)
+	ret := txn.tree.Delete(key)
+	assert(ret == 0)
+	txn.undo = append(txn.undo, undoRecord{undoDelete, key, value, deadline})
+	return 0
+}
+
+// Update behaves like BPlusTree.Update.
+func (txn *Txn) Update(key KeyType, data DataType) int {
+	txn.check()
+	value, ok := txn.tree.Search(key)
+	if !ok {
+		return -1
+	}
+	ret := txn.tree.Update(key, data)
+	assert(ret == 0)
+	txn.undo = append(txn.undo, undoRecord{op: undoUpdate, key: key, value: value})
+	return 0
+}
+
+// Search behaves like BPlusTree.Search and sees the transaction's writes.
+func (txn *Txn) Search(key KeyType) (DataType, bool) {
+	txn.check()
+	return txn.tree.Search(key)
+}
+
+// Ascend behaves like BPlusTree.Ascend and sees the transaction's writes.
+func (txn *Txn) Ascend(lo, hi KeyType) iter.Seq2[KeyType, DataType] {
+	txn.check()
+	return txn.tree.Ascend(lo, hi)
+}
+
+// Commit keeps the transaction's writes and closes it.
+func (txn *Txn) Commit() error {
+	if txn.done {
+		return ErrTxnDone
+	}
+	txn.finish()
+	txn.tree.evict()
+	return nil
+}
+
+// Rollback undoes the transaction's writes in reverse order and closes it.
+// In multi mode the same pairs are restored, but a deleted key comes back
+// after the duplicates still in the tree rather than at its old place.
+func (txn *Txn) Rollback() error {
+	if txn.done {
+		return ErrTxnDone
+	}
+	tree := txn.tree
+	for i := len(txn.undo) - 1; i >= 0; i-- {
+		rec := txn.undo[i]
+		var ret int
+		switch rec.op {
+		case undoInsert:
+			if tree.multi {
+				ret = tree.DeleteOne(rec.key, rec.value)
+			} else {
+				ret = tree.Delete(rec.key)
+			}
+		case undoDelete:
+			ret = tree.Insert(rec.key, rec.value)
+			if rec.deadline != 0 {
+				tree.setDeadline(rec.key, rec.deadline)
+			}
+		case undoUpdate:
+			ret = tree.Update(rec.key, rec.value)
+		}
+		assert(ret == 0)
+	}
+	txn.finish()
+	return nil
+}
+
+func (txn *Txn) finish() {
+	txn.undo = nil
+	txn.done = true
+	txn.tree.txn = nil
+}
@@ -0,0 +1,123 @@
+package bplustree
+
+import (
+	"cmp"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+/** the pairs of the tree, sorted by value as well when sortValues is set */
+func treeContents(tree *BPlusTree, sortValues bool) string {
+	var kvs [][2]int
+	for it := tree.First(); it.Valid(); it.Next() {
+		kvs = append(kvs, [2]int{it.Key(), it.Value()})
+	}
+	if sortValues {
+		slices.SortFunc(kvs, func(a, b [2]int) int {
+			return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+		})
+	}
+	return fmt.Sprint(kvs)
+}
+
+func TestTxn(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, multi := range []bool{false, true} {
+		var opts []Option
+		if multi {
+			opts = append(opts, Multi())
+		}
+		tree := New(3, 3, opts...)
+		for i := 0; i < 200; i++ {
+			tree.Insert(r.Intn(100), i)
+		}
+		for round := 0; round < 100; round++ {
+			before := treeContents(tree, multi)
+			txn := tree.Begin()
+			for i := 0; i < r.Intn(100); i++ {
+				key := r.Intn(100)
+				if r.Intn(2) == 0 {
+					ret := txn.Insert(key, round*1000+i)
+					if v, ok := txn.Search(key); ret == 0 && !multi && (!ok || v != round*1000+i) {
+						t.Fatalf("multi %v: own insert of %d not visible", multi, key)
+					}
+				} else {
+					txn.Delete(key)
+				}
+			}
+			if err := tree.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			if r.Intn(2) == 0 {
+				if err := txn.Commit(); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			if err := txn.Rollback(); err != nil {
+				t.Fatal(err)
+			}
+			if err := tree.Verify(); err != nil {
+				t.Fatal(err)
+			}
+			after := treeContents(tree, multi)
+			if after != before {
+				t.Fatalf("multi %v: rollback left\n%s\nwant\n%s", multi, after, before)
+			}
+			if txn.Commit() != ErrTxnDone || txn.Rollback() != ErrTxnDone {
+				t.Fatal("finished transaction accepted Commit or Rollback")
+			}
+		}
+	}
+}
+
+func TestTxnNested(t *testing.T) {
+	tree := New(4, 4)
+	txn := tree.Begin()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("second Begin did not panic")
+		}
+		txn.Rollback()
+		tree.Begin().Commit()
+	}()
+	tree.Begin()
+}
+
+func TestTxnExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tree := New(3, 3, WithClock(func() time.Time { return now }))
+	for k := 0; k < 10; k++ {
+		tree.InsertWithTTL(k, k, time.Duration(k+1)*time.Minute)
+	}
+	now = now.Add(150 * time.Second)
+	txn := tree.Begin()
+	if _, ok := txn.Search(0); ok || tree.Len() != 10 {
+		t.Fatalf("expired key found or removed, %d keys left", tree.Len())
+	}
+	if ret := txn.Insert(1, 100); ret != 0 {
+		t.Fatal("insert over an expired key failed")
+	}
+	if ret := txn.Delete(5); ret != 0 {
+		t.Fatal("delete of a live key failed")
+	}
+	if err := txn.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 10 {
+		t.Fatalf("%d keys after Rollback", tree.Len())
+	}
+	if d, ok := tree.TTL(1); !ok || d != -30*time.Second {
+		t.Fatalf("TTL(1) = %v, %v after Rollback", d, ok)
+	}
+	if d, ok := tree.TTL(5); !ok || d != 210*time.Second {
+		t.Fatalf("TTL(5) = %v, %v after Rollback", d, ok)
+	}
+	/* lazy expiry resumes once the transaction is closed */
+	if _, ok := tree.Search(1); ok || tree.Len() != 9 {
+		t.Fatalf("expired key 1 found, %d keys left", tree.Len())
+	}
+}
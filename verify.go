@@ -0,0 +1,307 @@
+package bplustree
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Verify checks the structural invariants of the tree: key order within and
+// across nodes, separators against the keys of their subtrees, node fill
+// bounds, parent links, the leaf and non-leaf sibling rings, uniform leaf
+// depth, the entry count and the cached node hashes and aggregates. It
+// returns the first violation found.
+func (tree *BPlusTree) Verify() error {
+	if tree.root == nil {
+		if tree.count != 0 {
+			return fmt.Errorf("bplustree: empty tree counts %d entries", tree.count)
+		}
+		return nil
+	}
+	if bn := getNode(tree.root); bn.parent != nil {
+		return fmt.Errorf("bplustree: root has a parent")
+	}
+
+	v := verifier{tree: tree, levels: make([][]*bplusNonLeaf, tree.level)}
+	if err := v.node(tree.root, 0, nil, nil); err != nil {
+		return err
+	}
+	if v.count != tree.count {
+		return fmt.Errorf("bplustree: leaves hold %d entries, tree counts %d", v.count, tree.count)
+	}
+
+	/* leaf ring, starting at firstLeaf */
+	if v.leaves[0] != tree.firstLeaf {
+		return fmt.Errorf("bplustree: firstLeaf is not the leftmost leaf")
+	}
+	for i, leaf := range v.leaves {
+		next := v.leaves[(i+1)%len(v.leaves)]
+		if leaf.next != next || next.prev != leaf {
+			return fmt.Errorf("bplustree: leaf ring broken after leaf %d", i)
+		}
+	}
+	if tree.merkle {
+		if _, err := v.hash(tree.root); err != nil {
+			return err
+		}
+	}
+	if tree.monoid != nil {
+		if _, err := v.aggregate(tree.root); err != nil {
+			return err
+		}
+	}
+	/* non-leaf rings, one per level */
+	for level, nodes := range v.levels {
+		for i, nl := range nodes {
+			next := nodes[(i+1)%len(nodes)]
+			if nl.next != next || next.prev != nl {
+				return fmt.Errorf("bplustree: non-leaf ring broken at level %d after node %d", level, i)
+			}
+		}
+	}
+	return nil
+}
+
+type verifier struct {
+	tree   *BPlusTree
+	count  int
+	leaves []*bplusLeaf
+	levels [][]*bplusNonLeaf
+}
+
+// inRange reports whether key fits between the separators lo and hi of the
+// enclosing subtree; nil means unbounded. Equal keys may sit on both sides
+// of a separator in a multimap.
+func (v *verifier) inRange(key KeyType, lo, hi *KeyType) bool {
+	if lo != nil && key < *lo {
+		return false
+	}
+	if hi != nil && (key > *hi || key == *hi && !v.tree.multi) {
+		return false
+	}
+	return true
+}
+
+func (v *verifier) ordered(a, b KeyType) bool {
+	return a < b || a == b && v.tree.multi
+}
+
+func (v *verifier) node(n node, depth int, lo, hi *KeyType) error {
+	tree := v.tree
+	if leaf, ok := n.(*bplusLeaf); ok {
+		if depth != tree.level {
+			return fmt.Errorf("bplustree: leaf at depth %d, tree level is %d", depth, tree.level)
+		}
+		if leaf.typ != nodeLeaf {
+			return fmt.Errorf("bplustree: leaf tagged as non-leaf")
+		}
+		if leaf.entries > tree.entries || leaf.entries < 1 ||
+			leaf.parent != nil && leaf.entries < (tree.entries+1)/2 {
+			return fmt.Errorf("bplustree: leaf holds %d entries, capacity %d", leaf.entries, tree.entries)
+		}
+		for i := 0; i < leaf.entries; i++ {
+			key := leaf.kvs[i].key
+			if i > 0 && !v.ordered(leaf.kvs[i-1].key, key) {
+				return fmt.Errorf("bplustree: leaf key %d out of order after %d", key, leaf.kvs[i-1].key)
+			}
+			if !v.inRange(key, lo, hi) {
+				return fmt.Errorf("bplustree: leaf key %d outside its separators", key)
+			}
+		}
+		v.count += leaf.entries
+		v.leaves = append(v.leaves, leaf)
+		return nil
+	}
+
+	nl := n.(*bplusNonLeaf)
+	if depth >= tree.level {
+		return fmt.Errorf("bplustree: non-leaf at depth %d, tree level is %d", depth, tree.level)
+	}
+	if nl.typ != nodeNonLeaf {
+		return fmt.Errorf("bplustree: non-leaf tagged as leaf")
+	}
+	if nl.children > tree.order || nl.children < 2 ||
+		nl.parent != nil && nl.children < (tree.order+1)/2 {
+		return fmt.Errorf("bplustree: non-leaf has %d children, order %d", nl.children, tree.order)
+	}
+	v.levels[depth] = append(v.levels[depth], nl)
+	start := v.count
+	for i := 0; i < nl.children-1; i++ {
+		if i > 0 && !v.ordered(nl.key[i-1], nl.key[i]) {
+			return fmt.Errorf("bplustree: separator %d out of order after %d", nl.key[i], nl.key[i-1])
+		}
+		if lo != nil && nl.key[i] < *lo || hi != nil && nl.key[i] > *hi {
+			return fmt.Errorf("bplustree: separator %d outside its parent's separators", nl.key[i])
+		}
+	}
+	for i := 0; i < nl.children; i++ {
+		bn := getNode(nl.subPtr[i])
+		if bn.parent != nl || bn.parentKeyIdx != i-1 {
+			return fmt.Errorf("bplustree: child %d of a non-leaf at depth %d has a wrong parent link", i, depth)
+		}
+		l, h := lo, hi
+		if i > 0 {
+			l = &nl.key[i-1]
+		}
+		if i < nl.children-1 {
+			h = &nl.key[i]
+		}
+		if err := v.node(nl.subPtr[i], depth+1, l, h); err != nil {
+			return err
+		}
+	}
+	if n := v.count - start; nl.size != n {
+		return fmt.Errorf("bplustree: non-leaf at depth %d counts %d entries, its leaves hold %d", depth, nl.size, n)
+	}
+	return nil
+}
+
+// hash recomputes the hash of the subtree rooted at n and checks it
+// against every cached hash marked up to date.
+func (v *verifier) hash(n node) (uint64, error) {
+	var h uint64
+	if leaf, ok := n.(*bplusLeaf); ok {
+		for i := 0; i < leaf.entries; i++ {
+			h += entryHash(leaf.kvs[i].key, leaf.kvs[i].value)
+		}
+	} else {
+		nl := n.(*bplusNonLeaf)
+		for i := 0; i < nl.children; i++ {
+			ch, err := v.hash(nl.subPtr[i])
+			if err != nil {
+				return 0, err
+			}
+			h += ch
+		}
+	}
+	if bn := getNode(n); bn.hashed && bn.hash != h {
+		return 0, fmt.Errorf("bplustree: stale cached hash %#x, subtree hashes to %#x", bn.hash, h)
+	}
+	return h, nil
+}
+
+func (v *verifier) aggregate(n node) (DataType, error) {
+	m := v.tree.monoid
+	a := m.Identity
+	if leaf, ok := n.(*bplusLeaf); ok {
+		for i := 0; i < leaf.entries; i++ {
+			a = m.Combine(a, leaf.kvs[i].value)
+		}
+	} else {
+		nl := n.(*bplusNonLeaf)
+		for i := 0; i < nl.children; i++ {
+			ca, err := v.aggregate(nl.subPtr[i])
+			if err != nil {
+				return 0, err
+			}
+			a = m.Combine(a, ca)
+		}
+	}
+	if bn := getNode(n); bn.aggregated && bn.agg != a {
+		return 0, fmt.Errorf("bplustree: stale cached aggregate %d, subtree aggregates to %d", bn.agg, a)
+	}
+	return a, nil
+}
+
+// Verify checks the structure of the tree: every node within the page
+// size, separators and keys in order, parent links, leaf depths, the
+// packed suffixes of each leaf, the leaf ring and the entry count. It
+// returns the first violation found, or nil.
+func (tree *BytesTree) Verify() error {
+	if tree.root == nil {
+		if tree.count != 0 || tree.firstLeaf != nil {
+			return fmt.Errorf("bplustree: empty bytes tree counts %d entries", tree.count)
+		}
+		return nil
+	}
+	if getBytesNode(tree.root).parent != nil {
+		return fmt.Errorf("bplustree: root has a parent")
+	}
+
+	v := bytesVerifier{tree: tree}
+	if err := v.node(tree.root, 0, nil, nil); err != nil {
+		return err
+	}
+	if v.count != tree.count {
+		return fmt.Errorf("bplustree: leaves hold %d entries, tree counts %d", v.count, tree.count)
+	}
+	if v.leaves[0] != tree.firstLeaf {
+		return fmt.Errorf("bplustree: firstLeaf is not the leftmost leaf")
+	}
+	for i, leaf := range v.leaves {
+		next := v.leaves[(i+1)%len(v.leaves)]
+		if leaf.next != next || next.prev != leaf {
+			return fmt.Errorf("bplustree: leaf ring broken after leaf %d", i)
+		}
+	}
+	return nil
+}
+
+type bytesVerifier struct {
+	tree   *BytesTree
+	count  int
+	leaves []*bytesLeaf
+}
+
+// node checks the subtree rooted at n, whose keys must lie in [lo, hi);
+// nil means unbounded.
+func (v *bytesVerifier) node(n bytesNode, depth int, lo, hi []byte) error {
+	tree := v.tree
+	if leaf, ok := n.(*bytesLeaf); ok {
+		if depth != tree.level {
+			return fmt.Errorf("bplustree: leaf at depth %d, tree level is %d", depth, tree.level)
+		}
+		if len(leaf.values) == 0 || leaf.size() > tree.pageSize {
+			return fmt.Errorf("bplustree: leaf holds %d keys in %d bytes, page size %d", len(leaf.values), leaf.size(), tree.pageSize)
+		}
+		if len(leaf.off) != len(leaf.values)+1 || leaf.off[0] != 0 || leaf.off[len(leaf.values)] != len(leaf.buf) {
+			return fmt.Errorf("bplustree: leaf suffix offsets do not cover its buffer")
+		}
+		for i := range leaf.values {
+			if leaf.off[i] > leaf.off[i+1] {
+				return fmt.Errorf("bplustree: leaf suffix offset %d decreases", i+1)
+			}
+			key := leaf.key(i)
+			if i > 0 && bytes.Compare(leaf.key(i-1), key) >= 0 {
+				return fmt.Errorf("bplustree: leaf key %q out of order after %q", key, leaf.key(i-1))
+			}
+			if lo != nil && bytes.Compare(key, lo) < 0 || hi != nil && bytes.Compare(key, hi) >= 0 {
+				return fmt.Errorf("bplustree: leaf key %q outside its separators", key)
+			}
+		}
+		v.count += len(leaf.values)
+		v.leaves = append(v.leaves, leaf)
+		return nil
+	}
+
+	nl := n.(*bytesNonLeaf)
+	if depth >= tree.level {
+		return fmt.Errorf("bplustree: non-leaf at depth %d, tree level is %d", depth, tree.level)
+	}
+	if len(nl.subPtr) < 2 || len(nl.key) != len(nl.subPtr)-1 || nl.size() > tree.pageSize {
+		return fmt.Errorf("bplustree: non-leaf has %d children in %d bytes, page size %d", len(nl.subPtr), nl.size(), tree.pageSize)
+	}
+	for i, key := range nl.key {
+		if i > 0 && bytes.Compare(nl.key[i-1], key) >= 0 {
+			return fmt.Errorf("bplustree: separator %q out of order after %q", key, nl.key[i-1])
+		}
+		if lo != nil && bytes.Compare(key, lo) < 0 || hi != nil && bytes.Compare(key, hi) > 0 {
+			return fmt.Errorf("bplustree: separator %q outside its parent's separators", key)
+		}
+	}
+	for i, c := range nl.subPtr {
+		if bn := getBytesNode(c); bn.parent != nl || bn.parentKeyIdx != i-1 {
+			return fmt.Errorf("bplustree: child %d of a non-leaf at depth %d has a wrong parent link", i, depth)
+		}
+		l, h := lo, hi
+		if i > 0 {
+			l = nl.key[i-1]
+		}
+		if i < len(nl.key) {
+			h = nl.key[i]
+		}
+		if err := v.node(c, depth+1, l, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
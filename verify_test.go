@@ -0,0 +1,55 @@
+package bplustree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, cfg := range [][2]int{{3, 2}, {3, 3}, {4, 4}, {5, 3}, {7, 10}} {
+		for _, multi := range []bool{false, true} {
+			var tree *BPlusTree
+			if multi {
+				tree = New(cfg[0], cfg[1], Multi())
+			} else {
+				tree = New(cfg[0], cfg[1])
+			}
+			for i := 0; i < 3000; i++ {
+				k := r.Intn(100)
+				if r.Intn(2) == 0 {
+					tree.Insert(k, i)
+				} else {
+					tree.Delete(k)
+				}
+				if err := tree.Verify(); err != nil {
+					t.Fatalf("%v multi=%v step %d: %v", cfg, multi, i, err)
+				}
+			}
+		}
+	}
+}
+
+func TestVerifyDetectsCorruption(t *testing.T) {
+	tree := New(3, 3)
+	for i := 0; i < 20; i++ {
+		tree.Insert(i, i)
+	}
+	leaf := tree.firstLeaf.next
+	leaf.kvs[0].key, leaf.kvs[1].key = leaf.kvs[1].key, leaf.kvs[0].key
+	if tree.Verify() == nil {
+		t.Fatalf("swapped keys not detected")
+	}
+	leaf.kvs[0].key, leaf.kvs[1].key = leaf.kvs[1].key, leaf.kvs[0].key
+
+	leaf.parentKeyIdx++
+	if tree.Verify() == nil {
+		t.Fatalf("wrong parentKeyIdx not detected")
+	}
+	leaf.parentKeyIdx--
+
+	tree.firstLeaf = leaf
+	if tree.Verify() == nil {
+		t.Fatalf("wrong firstLeaf not detected")
+	}
+}